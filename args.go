@@ -0,0 +1,103 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/help"
+	"commandgo/values"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Args gets the flags of this map, with their current values, as the command line arguments which would set them.
+// e.g. for a subprocess, or logging how a command was run.  Each flag is given once, by its longest name.
+// Flags with their zero value, or with a Default and that default value, are omitted.
+// Only the flags of this map are given, not those of its sub maps.  Secret flags are never given.
+func (c Commands) Args() ([]string, error) {
+	var args []string
+	ix := c.flagIndex()
+	for _, k := range c.sortedKeys() {
+		f := ix.flag(c, k)
+		if k == WildcardKey || f == nil || f.names[0] != k || f.value == &help.HelpRequested {
+			// each flag given once, by its longest name
+			continue
+		}
+		// options, such as Secret or Counter, may be on any alias
+		if f.options.Secret {
+			continue
+		}
+		fa, err := flagArgs(k, f.options)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fa...)
+	}
+	if cmd, ok := c[WildcardKey]; ok && isWildcard(cmd) {
+		wa, err := wildcardArgs(cmd)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, wa...)
+	}
+	return args, nil
+}
+
+// flagArgs gets the arguments which set the given flag to its current value.
+func flagArgs(name string, fl Flag) ([]string, error) {
+	v := reflect.ValueOf(fl.Value).Elem()
+	s, err := values.ValueToString(fl.Value)
+	if err != nil {
+		return nil, err
+	}
+	if fl.Default != "" {
+		dv, err := values.ValueFromString(fl.Default, v.Type())
+		if err != nil {
+			return nil, err
+		}
+		if ds, err := values.ValueToString(dv); err == nil && ds == s {
+			return nil, nil
+		}
+	} else if v.IsZero() {
+		return nil, nil
+	}
+	if fl.Counter {
+		args := make([]string, v.Int())
+		for i := range args {
+			args[i] = name
+		}
+		return args, nil
+	}
+	// pointers to bools, left nil until set, are given as the bool they point to
+	bv := v
+	for bv.Kind() == reflect.Ptr && !bv.IsNil() {
+		bv = bv.Elem()
+	}
+	if bv.Kind() == reflect.Bool && bv.Bool() && fl.NoOptValue == "" {
+		return []string{name}, nil
+	}
+	if bv.Kind() == reflect.Bool || isBoolFlag(fl) || fl.NoOptValue != "" || s == "" || arguments.IsFlag(s) {
+		// values which can not follow the flag as the next argument
+		return []string{strings.Join([]string{name, s}, "=")}, nil
+	}
+	return []string{name, s}, nil
+}
+
+// wildcardArgs gets the arguments of all the unknown flags collected by the given wildcard mapping.
+func wildcardArgs(cmd interface{}) ([]string, error) {
+	mv := reflect.ValueOf(target(cmd)).Elem()
+	keys := mv.MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.String()
+	}
+	sort.Strings(names)
+	var args []string
+	for _, n := range names {
+		s, err := values.ValueToString(mv.MapIndex(reflect.ValueOf(n).Convert(mv.Type().Key())).Interface())
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.Join([]string{flagName(n), s}, "="))
+	}
+	return args, nil
+}
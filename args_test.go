@@ -0,0 +1,58 @@
+package commandgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArgsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "counter", args: []string{"-vvv"}, want: []string{"--verbose", "--verbose", "--verbose"}},
+		{name: "counter by alias", args: []string{"--verbose", "--verbose"}, want: []string{"--verbose", "--verbose"}},
+		{name: "counter by both", args: []string{"-v", "--verbose"}, want: []string{"--verbose", "--verbose"}},
+		{name: "aliased flag", args: []string{"-n", "app"}, want: []string{"--name", "app"}},
+		{name: "aliased bool", args: []string{"-f"}, want: []string{"--force"}},
+		{name: "secret alias", args: []string{"--password", "hunter2", "-f"}, want: []string{"--force"}},
+		{name: "all", args: []string{"-fvn", "app", "-v"}, want: []string{"--force", "--name", "app", "--verbose", "--verbose"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verbose int
+			var name, password string
+			var force bool
+			c := Commands{
+				"":           func() {},
+				"-v":         Flag{Value: &verbose, Counter: true},
+				"--verbose":  &verbose,
+				"-n":         Flag{Value: &name, Usage: "the name"},
+				"--name":     &name,
+				"-f":         &force,
+				"--force":    &force,
+				"-p":         Flag{Value: &password, Secret: true},
+				"--password": &password,
+			}
+			if _, err := c.Run(tt.args...); err != nil {
+				t.Fatalf("Run(%v) error = %v", tt.args, err)
+			}
+			want := []interface{}{verbose, name, force}
+			args, err := c.Args()
+			if err != nil {
+				t.Fatalf("Args() error = %v", err)
+			}
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Fatalf("Args() = %v, want %v", args, tt.want)
+			}
+			verbose, name, force = 0, "", false
+			if _, err := c.Run(args...); err != nil {
+				t.Fatalf("Run(%v) error = %v", args, err)
+			}
+			if got := []interface{}{verbose, name, force}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Run(%v) set %v, want %v", args, got, want)
+			}
+		})
+	}
+}
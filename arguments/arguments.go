@@ -5,6 +5,10 @@ import (
 	"strings"
 )
 
+// Terminator is the argument marking the end of the flags in a command line.
+// All arguments following it are parameters, even those beginning with a '-'
+const Terminator = "--"
+
 type Arguments interface {
 	// Command gets the first argument from the command line, only if it is NOT a flag.
 	// If the cmd line begins with a flag argument, command returns empty
@@ -16,6 +20,7 @@ type Arguments interface {
 	Argument(name string) *Argument
 
 	// Flags gets all the Arguments with their parameters, with names begining with a '-'
+	// Any arguments following a Terminator are not flags.
 	Flags() []*Argument
 
 	// Remove removes the given argument from the command line.
@@ -30,6 +35,10 @@ type Argument struct {
 	Name       string
 	Position   int
 	Parameters []string
+
+	// Assigned is true when the flag was given its value in the same argument, following an '='. e.g. "--color=never"
+	// Assigned arguments have that value as their only parameter.
+	Assigned bool
 }
 
 func (a arguments) CommandLine() []string {
@@ -38,6 +47,7 @@ func (a arguments) CommandLine() []string {
 
 type arguments struct {
 	cmdline []string
+	numeric []string
 }
 
 func (a arguments) IsEmpty() bool {
@@ -45,7 +55,7 @@ func (a arguments) IsEmpty() bool {
 }
 
 func (a arguments) Command() string {
-	if a.IsEmpty() || strings.HasPrefix(a.cmdline[0], "-") {
+	if a.IsEmpty() || a.isFlag(a.cmdline[0]) {
 		// no command, all flags or empty
 		return ""
 	}
@@ -55,11 +65,13 @@ func (a arguments) Command() string {
 func (a arguments) Flags() []*Argument {
 	var flags []*Argument
 	for i, cmd := range a.cmdline {
-		if !strings.HasPrefix(cmd, "-") {
+		if cmd == Terminator {
+			break
+		}
+		if !a.isFlag(cmd) {
 			continue
 		}
 		arg := a.newArg(cmd, i)
-		i += len(arg.Parameters)
 		flags = append(flags, arg)
 	}
 	return flags
@@ -80,8 +92,11 @@ func (a arguments) Argument(name string) *Argument {
 }
 
 func (a *arguments) Remove(arg *Argument) error {
-	i := arg.Position + 1 + len(arg.Parameters)
-	if arg.Position > len(a.cmdline) || i > len(a.cmdline) {
+	i := arg.Position + 1
+	if !arg.Assigned {
+		i += len(arg.Parameters)
+	}
+	if arg.Position >= len(a.cmdline) || i > len(a.cmdline) {
 		return fmt.Errorf("invaid arg position %d.  Command line is %d long", arg.Position, len(a.cmdline))
 	}
 	a.cmdline = append(a.cmdline[:arg.Position], a.cmdline[i:]...)
@@ -94,7 +109,7 @@ func (a arguments) parameters(position int) []string {
 	var params []string
 	for i := position + 1; i < len(a.cmdline); i++ {
 		// Stop gathering parameters at the next flag or end of cmdline
-		if strings.HasPrefix(a.cmdline[i], "-") {
+		if a.isFlag(a.cmdline[i]) {
 			break
 		}
 		params = append(params, a.cmdline[i])
@@ -103,6 +118,16 @@ func (a arguments) parameters(position int) []string {
 }
 
 func (a arguments) newArg(name string, position int) *Argument {
+	if a.isFlag(name) {
+		if parts := strings.SplitN(name, "=", 2); len(parts) == 2 {
+			return &Argument{
+				Name:       parts[0],
+				Position:   position,
+				Parameters: []string{parts[1]},
+				Assigned:   true,
+			}
+		}
+	}
 	return &Argument{
 		Name:       name,
 		Position:   position,
@@ -110,6 +135,45 @@ func (a arguments) newArg(name string, position int) *Argument {
 	}
 }
 
-func NewArguments(args []string) Arguments {
-	return &arguments{cmdline: args}
+// IsFlag checks if the given argument is a flag, beginning with a '-'.
+// Negative numbers, a '-' followed by a digit or a '.' and a digit, such as "-5", "-0.5" or "-2h", are not flags.
+func IsFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	n := strings.TrimPrefix(arg[1:], ".")
+	return n == "" || n[0] < '0' || n[0] > '9'
+}
+
+// isFlag checks if the given argument is a flag, or one of the numeric flags of these arguments, optionally followed by an '=' and its value.
+func (a arguments) isFlag(arg string) bool {
+	if IsFlag(arg) {
+		return true
+	}
+	if len(a.numeric) == 0 || !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	name := strings.SplitN(arg, "=", 2)[0]
+	for _, n := range a.numeric {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewArguments creates the Arguments of the given command line.
+// Any of the given numeric flags, such as "-4", are read as flags, rather than the negative numbers they would otherwise be.
+func NewArguments(args []string, numericFlags ...string) Arguments {
+	return &arguments{cmdline: args, numeric: numericFlags}
+}
+
+// RemoveTerminator removes the first Terminator from the given arguments, if present.
+func RemoveTerminator(args []string) []string {
+	for i, arg := range args {
+		if arg == Terminator {
+			return append(append([]string{}, args[:i]...), args[i+1:]...)
+		}
+	}
+	return args
 }
@@ -0,0 +1,32 @@
+package arguments
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		numeric []string
+		want    []string
+	}{
+		{name: "flags", args: []string{"-v", "x", "--name", "a"}, want: []string{"-v", "--name"}},
+		{name: "negative numbers", args: []string{"--count", "-3", "-0.5", "-2h"}, want: []string{"--count"}},
+		{name: "numeric flag", args: []string{"-4", "x", "-6"}, numeric: []string{"-4"}, want: []string{"-4"}},
+		{name: "numeric flag assigned", args: []string{"-4=true", "-6"}, numeric: []string{"-4"}, want: []string{"-4"}},
+		{name: "terminator", args: []string{"-v", "--", "-q"}, want: []string{"-v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range NewArguments(tt.args, tt.numeric...).Flags() {
+				got = append(got, f.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package arguments
+
+import "fmt"
+
+// MaxArguments is the maximum number of arguments a command line may contain. Zero, the default, is unlimited.
+var MaxArguments int
+
+// MaxArgumentLength is the maximum length, in bytes, of any single argument. Zero, the default, is unlimited.
+var MaxArgumentLength int
+
+// CheckLimits checks the given arguments are within the MaxArguments and MaxArgumentLength limits.
+// returns an error describing the first limit exceeded, if any.
+func CheckLimits(args []string) error {
+	if MaxArguments > 0 && len(args) > MaxArguments {
+		return fmt.Errorf("too many arguments.  %d given, limit is %d", len(args), MaxArguments)
+	}
+	if MaxArgumentLength > 0 {
+		for i, arg := range args {
+			if len(arg) > MaxArgumentLength {
+				return fmt.Errorf("argument %d is too long.  %d bytes, limit is %d", i+1, len(arg), MaxArgumentLength)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package arguments
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+// ResponseFiles, when true, replaces any argument beginning with an '@' with the arguments in the file it names.
+// e.g. "@args.txt" is replaced with the arguments in args.txt.  Arguments in the file are separated by spaces or line breaks,
+// and may be quoted with double or single quotes to include spaces.
+// Arguments following a Terminator are not replaced.  As every argument beginning with an '@' is a response file,
+// flag values read from a file must be given following an '='. e.g. --config=@settings.json
+var ResponseFiles bool
+
+// ExpandResponseFiles replaces any response file arguments, in the given arguments, with the arguments they contain, when ResponseFiles is set.
+func ExpandResponseFiles(args []string) ([]string, error) {
+	if !ResponseFiles {
+		return args, nil
+	}
+	var expanded []string
+	for i, arg := range args {
+		if arg == Terminator {
+			return append(expanded, args[i:]...), nil
+		}
+		if len(arg) < 2 || arg[0] != '@' {
+			expanded = append(expanded, arg)
+			continue
+		}
+		by, err := ioutil.ReadFile(arg[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response file %s  %v", arg[1:], err)
+		}
+		rargs, err := splitResponse(string(by))
+		if err != nil {
+			return nil, fmt.Errorf("response file %s is invalid  %v", arg[1:], err)
+		}
+		expanded = append(expanded, rargs...)
+	}
+	return expanded, nil
+}
+
+// splitResponse splits the given response file content into its arguments, separated by white space, outside of quotes.
+func splitResponse(s string) ([]string, error) {
+	var args []string
+	var sb strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			sb.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("missing closing %c quote", quote)
+	}
+	if inArg {
+		args = append(args, sb.String())
+	}
+	return args, nil
+}
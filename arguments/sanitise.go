@@ -0,0 +1,63 @@
+package arguments
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitiseMode defines how arguments containing invalid UTF-8 or control characters are handled.
+type SanitiseMode int
+
+const (
+	// SanitiseNone leaves all arguments as they are given
+	SanitiseNone SanitiseMode = iota
+	// SanitiseReject fails any command line containing an invalid argument
+	SanitiseReject
+	// SanitiseStrip removes any invalid UTF-8 and control characters from the arguments
+	SanitiseStrip
+)
+
+// Sanitise is the SanitiseMode applied to all command lines before they are parsed.
+// Control characters include tabs and line breaks, as well as all other unicode control characters.
+var Sanitise = SanitiseNone
+
+// SanitiseArguments applies the current Sanitise mode to the given arguments.
+// returns the arguments, stripped when stripping is in effect, or an error if rejecting and an invalid argument is found.
+func SanitiseArguments(args []string) ([]string, error) {
+	switch Sanitise {
+	case SanitiseReject:
+		for i, arg := range args {
+			if err := checkArgument(arg); err != nil {
+				return nil, fmt.Errorf("argument %d %v", i+1, err)
+			}
+		}
+		return args, nil
+
+	case SanitiseStrip:
+		sargs := make([]string, len(args))
+		for i, arg := range args {
+			sargs[i] = strings.Map(func(r rune) rune {
+				if r == utf8.RuneError || unicode.IsControl(r) {
+					return -1
+				}
+				return r
+			}, arg)
+		}
+		return sargs, nil
+
+	default:
+		return args, nil
+	}
+}
+
+func checkArgument(arg string) error {
+	if !utf8.ValidString(arg) {
+		return fmt.Errorf("%q is not valid UTF-8", arg)
+	}
+	if strings.IndexFunc(arg, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%q contains control characters", arg)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package commandgo
+
+import (
+	"commandgo/values"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// BindStruct maps flags to all the exported fields of the given pointer to a struct.
+// Flag names are taken from a 'flag' tag on the field, or default to the field name in kebab case. e.g. LogLevel is --log-level
+// The flag tag is a comma delimited list of names, the first being the main name and the remainder aliases,
+// followed by any of the options "required" and "hidden".  e.g.  `flag:"output,o,required"`
+// Names without leading dashes are given one dash for single characters, otherwise two.
+// A flag tag of "-" skips the field, and "*" collects all unknown flags into the field, a map, as the WildcardKey.
+// A 'usage' tag gives the Usage of the flag.
+// Fields of nested structs are bound with the name of the struct field, and a dot, preceding their names.
+// e.g. the Host field of a DB struct field is --db.host.  Single character names, such as an alias "p", are not preceded.
+// Embedded structs have their fields bound without a preceding name.
+// Returns an error if the struct has fields which can not be parsed, or any flag name is already mapped.
+func (c Commands) BindStruct(v interface{}) error {
+	pv := reflect.ValueOf(v)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can not bind %T, must be a pointer to a struct", v)
+	}
+	var errs Errors
+	c.bindFields(pv.Elem(), "", &errs)
+	return errs.errorOrNil()
+}
+
+// bindFields maps flags to the exported fields of the given struct, with the given prefix preceding their names.
+func (c Commands) bindFields(sv reflect.Value, prefix string, errs *Errors) {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag, tagged := sf.Tag.Lookup("flag")
+		if tag == "-" {
+			continue
+		}
+		names, fl := parseFlagTag(tag)
+		if len(names) == 0 {
+			names = []string{kebabCase(sf.Name)}
+		}
+		if fv, ok := nestedStruct(sv.Field(i)); ok && names[0] != WildcardKey {
+			np := prefix
+			if !sf.Anonymous {
+				np = strings.Join([]string{prefix, strings.TrimLeft(names[0], "-"), "."}, "")
+			}
+			c.bindFields(fv, np, errs)
+			continue
+		}
+		if !values.IsSupported(sf.Type) {
+			if tagged {
+				*errs = append(*errs, fmt.Errorf("field %s, a %s, can not be parsed from the command line", sf.Name, sf.Type.String()))
+			}
+			continue
+		}
+		fl.Value = sv.Field(i).Addr().Interface()
+		fl.Usage = sf.Tag.Get("usage")
+		if names[0] == WildcardKey {
+			if !isWildcard(fl.Value) {
+				*errs = append(*errs, fmt.Errorf("field %s, a %s, can not collect unknown flags", sf.Name, sf.Type.String()))
+			} else if _, ok := c[WildcardKey]; ok {
+				*errs = append(*errs, fmt.Errorf("field %s can not collect unknown flags, they are already mapped", sf.Name))
+			} else {
+				c[WildcardKey] = fl.Value
+			}
+			continue
+		}
+		for _, n := range names {
+			if !strings.HasPrefix(n, "-") && len([]rune(n)) > 1 {
+				// single character names are not prefixed, remaining short flags
+				n = prefix + n
+			}
+			k := flagName(n)
+			if ek, ok := c.findKey(k); ok {
+				*errs = append(*errs, fmt.Errorf("flag %s for field %s is already mapped as %s", k, sf.Name, ek))
+				continue
+			}
+			c[k] = fl
+		}
+	}
+}
+
+// nestedStruct gets the struct of the given field, when it is a struct, or pointer to one, which can not be parsed itself.
+// nil pointers are set to a new struct.
+func nestedStruct(fv reflect.Value) (reflect.Value, bool) {
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || values.IsSupported(t) {
+		return reflect.Value{}, false
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(t))
+		}
+		fv = fv.Elem()
+	}
+	return fv, true
+}
+
+// parseFlagTag splits the given flag tag into its names and a Flag with any of its options set.
+func parseFlagTag(tag string) ([]string, Flag) {
+	var fl Flag
+	var names []string
+	for _, s := range strings.Split(tag, ",") {
+		s = strings.TrimSpace(s)
+		switch s {
+		case "":
+		case "required":
+			fl.Required = true
+		case "hidden":
+			fl.Hidden = true
+		default:
+			names = append(names, s)
+		}
+	}
+	return names, fl
+}
+
+// flagName gives the given name its leading dashes, if it has none.
+func flagName(name string) string {
+	if strings.HasPrefix(name, "-") {
+		return name
+	}
+	if len([]rune(name)) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// kebabCase converts the given CamelCase name into lower case, dash separated words.
+// e.g. LogLevel is log-level, URLPath is url-path
+func kebabCase(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteRune('-')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+package commandgo
+
+import (
+	"commandgo/functions"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Command wraps a func or method mapping with additional options, controlling how it is invoked.
+// A Command may be used as a mapping value in place of the func itself.
+// e.g. "update": commandgo.Command{Func: db.Update, LockFile: "/tmp/mydb.lock"}
+type Command struct {
+	// Func is the func or method invoked by the command
+	Func interface{}
+
+	// Usage describes the command to the user.
+	Usage string
+
+	// Examples are example command lines using the command, shown in its help.
+	Examples []string
+
+	// LockFile, when set, is the path of a lock file held for the duration of the invocation.
+	// Should the lock file already exist, the command fails without being invoked.
+	LockFile string
+
+	// StdinParameter, when set, is the position of a parameter, starting at 1, which is read from stdin when omitted.
+	// The parameter is omitted when the command line has no argument in that position.
+	// Reading from stdin fails when stdin is a terminal, rather than waiting on input, or when stdin is empty.
+	// Trailing line breaks are removed from the input.
+	StdinParameter int
+
+	// Raw, when true, performs no parsing of the command line following the command name.
+	// The entire remainder of the command line, is passed as is, to the func, which must have a single []string or ...string parameter.
+	// A leading "--" argument, following the command name, is removed.
+	// Only arguments preceding the command name are parsed for flags.  Raw has no effect on a default ("") mapping.
+	Raw bool
+
+	// EnabledWhen, when set, is checked before the command is invoked, giving false, and the reason why, when the command is not available.
+	// e.g. a feature not yet released, or a command needing an environment variable set.
+	// A disabled command fails with its reason and is not suggested for unknown commands.
+	EnabledWhen func() (bool, string)
+
+	// UnknownFlags, when set, is a pointer to a map with string keys, collecting the flags following the command name, which are not mapped.
+	// Flags are collected as they are by the WildcardKey, keyed by their name without leading dashes, and are removed from the parameters.
+	// e.g. "proxy": commandgo.Command{Func: Proxy, UnknownFlags: &ProxyOptions}, with ProxyOptions a map[string]string
+	// Flags already collected by a WildcardKey of the same map are not seen by the command.  Raw commands do not collect flags.
+	UnknownFlags interface{}
+}
+
+// enabled checks if the given mapping is available, returning the reason when it is not.
+func enabled(cmd interface{}) (bool, string) {
+	cm, ok := cmd.(Command)
+	if !ok || cm.EnabledWhen == nil {
+		return true, ""
+	}
+	return cm.EnabledWhen()
+}
+
+// checkEnabled checks the mapping of the given key is available.
+func (c Commands) checkEnabled(key string) error {
+	ok, reason := enabled(c[key])
+	if ok {
+		return nil
+	}
+	if reason == "" {
+		return fmt.Errorf("%s is not available", key)
+	}
+	return fmt.Errorf("%s is not available  %s", key, reason)
+}
+
+// invoke calls the func of the command, with the given arguments, applying any options the command has.
+func (cm Command) invoke(args []string) ([]interface{}, error) {
+	if !functions.IsFunc(cm.Func) {
+		return nil, fmt.Errorf("command is mapped to an unknown type %T", cm.Func)
+	}
+	if cm.LockFile != "" {
+		unlock, err := acquireLock(cm.LockFile)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+	if cm.StdinParameter > 0 && len(args) == cm.StdinParameter-1 {
+		in, err := readStdin()
+		if err != nil {
+			return nil, fmt.Errorf("missing argument %d  %v", cm.StdinParameter, err)
+		}
+		if in == "" {
+			return nil, fmt.Errorf("missing argument %d  no argument given and stdin is empty", cm.StdinParameter)
+		}
+		args = append(args, in)
+	}
+	if cm.Raw {
+		return functions.CallFuncRaw(cm.Func, args)
+	}
+	return functions.CallFunc(cm.Func, args...)
+}
+
+// readStdin reads all of stdin, failing if stdin is a terminal.
+func readStdin() (string, error) {
+	if isTerminal(os.Stdin) {
+		return "", fmt.Errorf("no argument given and stdin is a terminal")
+	}
+	by, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(by), "\r\n"), nil
+}
@@ -2,13 +2,14 @@ package commandgo
 
 import (
 	"commandgo/arguments"
+	"commandgo/config"
 	"commandgo/functions"
 	"commandgo/help"
 	"commandgo/values"
 	"fmt"
-	"log"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,6 +19,8 @@ import (
 // - A pointer to a global variable or field in an instance of a structure.
 // - A function or method on an instance of a structure.
 // - Another Commands map.  Sub maps are invoked when the key command from the parent map is called.
+// - A Command, wrapping a function or method with additional options.
+// - A Flag, wrapping a pointer to a variable or field with additional options.
 // A key may be an empty string, indicating it as the default mapping for that map.
 // i.e. if the first command arg is unknown, it is treated as a parameter when invoking the default mapping
 // Assignments are only applied at each map level. i.e. top level mappings are assigned first, then any sub map assignments afterwards.
@@ -26,6 +29,18 @@ type Commands map[string]interface{}
 
 type flagMap map[string]*arguments.Argument
 
+// InterspersedFlags, when true, the default, matches flags anywhere on the command line.
+// When false, flags are only matched up to the first argument which is neither a flag nor a flag value.
+// All the arguments from that point on are parameters, including any flags. e.g. "mytool run prog -its -flags"
+// passes "prog -its -flags" to run.  A sub command's map matches its own flags, from those following the sub command.
+var InterspersedFlags = true
+
+// Normalizer gives the form in which command line arguments and keys are compared, when they do not match exactly.
+// Arguments match any key with the same normalized form.  Set to nil for arguments to only match keys exactly.
+var Normalizer = NormalizeName
+
+var helpHint = fmt.Sprintf("use %s to list the available commands", help.HelpFlagFull)
+
 // RunArgs executes this commands using the os.Args array as the arguments to parse.
 // Same as calling Run(os.Args[1:])
 func (c Commands) RunArgs() ([]interface{}, error) {
@@ -35,49 +50,158 @@ func (c Commands) RunArgs() ([]interface{}, error) {
 // Run executes this commands using the given argument array
 // All arguments mapped to assignments (variables or fields) are extracted from the given array and applied.
 // All remaining arguments are used to call a command, the first being the command and any following are used as parameters for that call.
+// The map itself is not modified, but running it sets the variables and fields it maps, resetting them before each run,
+// so it is not safe to run concurrently, nor alongside any other map mapping the same variables.
 func (c Commands) Run(args ...string) ([]interface{}, error) {
-	// help flags are added to indicate if help requested.
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	args, err = arguments.ExpandResponseFiles(args)
+	if err != nil {
+		return nil, err
+	}
+	// restore defaults, of this map and its sub maps, before any values are applied
+	if err := c.Reset(); err != nil {
+		return nil, err
+	}
+	return c.run(cfg, args...)
+}
+
+// run executes this commands using the given argument array, and the config section for this map, if any.
+func (c Commands) run(cfg config.Section, args ...string) ([]interface{}, error) {
+	// help flags are added, to a copy of this map, to indicate if help requested.
 	// These prevents all other flags and commands being invoked.
-	if _, ok := c[help.HelpFlagShort]; !ok {
-		c[help.HelpFlagShort] = &help.HelpRequested
+	c = c.withHelpFlags()
+	ix := c.flagIndex()
+
+	if err := arguments.CheckLimits(args); err != nil {
+		return nil, err
+	}
+	args, err := arguments.SanitiseArguments(args)
+	if err != nil {
+		return nil, err
 	}
-	if _, ok := c[help.HelpFlagFull]; !ok {
-		c[help.HelpFlagFull] = &help.HelpRequested
+
+	// Raw commands take the remainder of the command line as it is. Only the arguments before them are parsed.
+	var raw []string
+	if i := c.rawCommandIndex(args, ix); i >= 0 {
+		args, raw = args[:i+1], args[i+1:]
+		if len(raw) > 0 && raw[0] == "--" {
+			raw = raw[1:]
+		}
 	}
 
 	var result []interface{}
 
 	// collect any flags from cmdline that are mapped in this map (removes them from args)
-	cargs := arguments.NewArguments(args)
-	flags := c.matchFlags(cargs)
-
-	// Invoke all the flags before invoking the command
-	v, err := c.invokeFlags(flags)
+	expanded := c.expandClusters(args, ix)
+	cargs := arguments.NewArguments(expanded, c.numericFlags()...)
+	subIndex := c.subMapIndex(expanded, ix)
+	flags, err := c.matchFlags(cargs, subIndex, c.flagsEnd(expanded, ix), ix)
 	if err != nil {
 		return nil, err
 	}
-	result = append(result, v...)
+	// unknown flags following a sub command are left for the sub map
+	wildEnd := c.flagsEnd(cargs.CommandLine(), ix)
+	if subIndex >= 0 {
+		wildEnd = c.commandPosition(cargs.CommandLine(), ix)
+	}
+	if err := c.wildcardFlags(cargs, wildEnd); err != nil {
+		return nil, err
+	}
+	c.environmentFlags(flags, ix)
+	if err := c.configFlags(flags, cfg, ix); err != nil {
+		return nil, err
+	}
+	if err := c.secretFlags(flags, ix); err != nil {
+		return nil, err
+	}
+
+	// Assign all the flags, before checking them, and invoking any flag funcs
+	if err := c.assignFlags(flags, ix); err != nil {
+		return nil, err
+	}
 
 	// Establish the command key, if any
 	ca := cargs.Command() // may be empty
+	params := cargs.CommandLine()
 	k, ok := c.findKey(ca)
-	if !ok {
+	if k == WildcardKey {
+		ok = false
+	}
+	if !ok && strings.EqualFold(ca, HelpCommand) {
+		// the help command word, when not mapped itself
+		return c.showHelp(k, params[1:])
+	}
+	if ok && ca != "" {
+		// command name is not a parameter of the command
+		params = params[1:]
+	} else {
 		// not known, check if default key available
 		k, ok = c.findKey("")
 	}
-	if help.HelpRequested {
-		return help.ShowHelp(k, args...), nil
+	if c.helpRequested(flags) {
+		return c.showHelp(k, cargs.CommandLine())
+	}
+	// help flags mapped to funcs are invoked without checking the other flags
+	if flags.helpKey() == "" {
+		if err := c.checkRequired(flags, ix); err != nil {
+			return nil, err
+		}
+		if err := c.checkRelated(flags, ix); err != nil {
+			return nil, err
+		}
+	}
+	// Invoke all the flag funcs before invoking the command
+	v, err := c.invokeFlags(flags)
+	if err != nil {
+		return nil, err
 	}
+	result = append(result, v...)
 	if !ok {
 		if ca != "" {
-			return nil, fmt.Errorf("%s is an unknown command", ca)
+			hint := helpHint
+			if sk := c.suggestKey(ca); sk != "" {
+				hint = fmt.Sprintf("did you mean %s?\n%s", sk, helpHint)
+			}
+			return nil, WithHint(fmt.Errorf("%s is an unknown command", ca), hint)
+		}
+		// only flags were given, show the usage of this map
+		var sb strings.Builder
+		if err := c.Help(&sb); err != nil {
+			return nil, WithHint(fmt.Errorf("no command found"), helpHint)
 		}
-		return nil, fmt.Errorf("no command found")
+		return nil, WithHint(fmt.Errorf("no command found"), strings.TrimRight(sb.String(), "\n"))
 	}
 
+	if err := c.checkEnabled(k); err != nil {
+		return nil, err
+	}
 	cmd := c[k]
-	ag := c.trimParameters(cmd, cargs.CommandLine())
-	v, err = c.invokeCommand(cmd, ag)
+	if cm, ok := cmd.(Command); ok && cm.UnknownFlags != nil && !cm.Raw {
+		if !isWildcard(cm.UnknownFlags) {
+			return nil, fmt.Errorf("%s UnknownFlags must be a pointer to a map with string keys", k)
+		}
+		pargs := arguments.NewArguments(params, c.numericFlags()...)
+		if err := collectFlags(cm.UnknownFlags, pargs, c.flagsEnd(params, ix)); err != nil {
+			return nil, err
+		}
+		params = pargs.CommandLine()
+	}
+	if !c.isSubmap(cmd) {
+		// sub maps remove their own terminator
+		params = arguments.RemoveTerminator(params)
+	}
+	ag := c.trimParameters(cmd, params)
+	if cm, ok := cmd.(Command); ok && cm.Raw {
+		ag = raw
+	}
+	if sc, ok := cmd.(Commands); ok {
+		v, err = sc.run(c.configSection(cfg, k), ag...)
+	} else {
+		v, err = c.invokeCommand(cmd, ag)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +212,11 @@ func (c Commands) Run(args ...string) ([]interface{}, error) {
 // returns any output from the command or an error
 func (c Commands) invokeCommand(cmd interface{}, args []string) ([]interface{}, error) {
 	if c.isSubmap(cmd) {
-		return (cmd.(Commands)).Run(args...)
+		return (cmd.(Commands)).run(nil, args...)
+	}
+
+	if cm, ok := cmd.(Command); ok {
+		return cm.invoke(args)
 	}
 
 	if c.isAssignment(cmd) {
@@ -96,7 +224,12 @@ func (c Commands) invokeCommand(cmd interface{}, args []string) ([]interface{},
 		if len(args) > 0 {
 			a = args[0]
 		}
-		return nil, values.SetValue(cmd, a)
+		nv := reflect.New(reflect.TypeOf(target(cmd)).Elem())
+		if err := values.SetValue(nv.Interface(), a); err != nil {
+			return nil, err
+		}
+		assign(target(cmd), nv.Elem())
+		return nil, nil
 	}
 
 	if functions.IsFunc(cmd) {
@@ -105,76 +238,398 @@ func (c Commands) invokeCommand(cmd interface{}, args []string) ([]interface{},
 	return nil, fmt.Errorf("command is mapped to an unknown type %T", cmd)
 }
 
-// invokeFlags executes the command of all the given flags.
-// Assignments (var/field pointers) are executed first, followed by any remaining func/method mappings.
+// withHelpFlags gets a copy of this map, with the help flags added, when not already mapped.
+// This map is unchanged.
+func (c Commands) withHelpFlags() Commands {
+	cc := make(Commands, len(c)+2)
+	for k, cmd := range c {
+		cc[k] = cmd
+	}
+	if _, ok := cc.findKey(help.HelpFlagShort); !ok {
+		cc[help.HelpFlagShort] = &help.HelpRequested
+	}
+	if _, ok := cc.findKey(help.HelpFlagFull); !ok {
+		cc[help.HelpFlagFull] = &help.HelpRequested
+	}
+	return cc
+}
+
+// assignFlags sets the assignments (var/field pointers) of all the given flags.
+// All the assignments are attempted, returning an error listing every flag which failed.
+// None are set when help is requested.
+func (c Commands) assignFlags(flags flagMap, ix flagIndex) error {
+	if c.helpRequested(flags) || flags.helpKey() != "" {
+		return nil
+	}
+	var errs Errors
+	for _, k := range flags.sortedKeys() {
+		if !c.isAssignment(c[k]) {
+			continue
+		}
+		if err := c.assignFlag(k, flags[k], ix); err != nil {
+			errs = append(errs, fmt.Errorf("%s %v", k, err))
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// invokeFlags executes the func/method mappings of all the given flags.
+// They are invoked once all the assignments are set and checked.
 // returns any return values from the func mappings or an error
 func (c Commands) invokeFlags(flags flagMap) ([]interface{}, error) {
 	// Check for help first to prevent others being invokes
-	hc := flags.HelpCommand()
-	if hc != nil {
-		return c.invokeCommand(hc, nil)
+	if c.helpRequested(flags) {
+		return nil, nil
 	}
-
-	funcM := map[string]*arguments.Argument{}
-	// perform the assignments first
-	for k, arg := range flags {
-		cmd := c[k]
-		if !c.isAssignment(cmd) {
-			funcM[k] = arg
+	if hk := flags.helpKey(); hk != "" {
+		return c.invokeCommand(c[hk], nil)
+	}
+	var result []interface{}
+	for _, k := range flags.sortedKeys() {
+		if c.isAssignment(c[k]) {
 			continue
 		}
-		_, err := c.invokeCommand(cmd, arg.Parameters)
-		if err != nil {
+		if err := c.checkEnabled(k); err != nil {
 			return nil, err
 		}
-	}
-	// perform any remaining flag functions,
-	var result []interface{}
-	for k, arg := range funcM {
-		iv, err := c.invokeCommand(c[k], arg.Parameters)
+		iv, err := c.invokeCommand(c[k], flags[k].Parameters)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, iv)
+		result = append(result, iv...)
 	}
 	return result, nil
 }
 
+// assignFlag sets the assignment of the given key with the value of the given argument, once the value is validated.
+// An invalid value leaves the variable unchanged.
+func (c Commands) assignFlag(key string, arg *arguments.Argument, ix flagIndex) error {
+	params := arg.Parameters
+	if arg.Position >= 0 {
+		// only values from the command line are read from files
+		var err error
+		if params, err = fileValues(params); err != nil {
+			return err
+		}
+	}
+	vals := []string{""}
+	if len(params) > 0 {
+		vals = params[:1]
+		if isFlagValue(c[key]) {
+			// flag.Values are Set with the value of every occurrence
+			vals = params
+		}
+	}
+	// options may be given on the Flag of any alias
+	fls := ix.flag(c, key).flags
+	if len(params) > 0 {
+		for _, value := range vals {
+			for _, fl := range fls {
+				if err := checkChoice(fl, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// the value is set on a copy of the variable, until it is valid
+	pv := reflect.ValueOf(target(c[key]))
+	nv := reflect.New(pv.Type().Elem())
+	nv.Elem().Set(copyValue(pv.Elem()))
+	for _, value := range vals {
+		if err := values.SetValue(nv.Interface(), value); err != nil {
+			return err
+		}
+	}
+	if err := validate(nv.Interface(), fls); err != nil {
+		return err
+	}
+	assign(pv.Interface(), nv.Elem())
+	return nil
+}
+
+// sortedKeys gets the keys of this flagMap in order, for consistent reporting.
+func (m flagMap) sortedKeys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // matches any flags found in the given arguments, with mapped flags in this Commands.
 // Any matched arguments are removed from the given args and copied to the resulting map.
-// returns a map keyed with the 'real' (not the command line arg) keys of this commands, mapping to the matching Argument
-func (c Commands) matchFlags(args arguments.Arguments) flagMap {
+// All remaining arguments, including unmatched flags and their parameters, keep their original order.
+// Should a flag appear more than once, by any of its names, the last one is used, unless it is a counter, which is given the number of appearances,
+// or a flag.Value, which is given the values of every appearance.
+// Flags following the given position of a sub map command, which the sub map also maps, are left for the sub map.
+// Flags at or beyond the given end position are not matched.
+// returns a map keyed with the 'real' (not the command line arg) keys of this commands, mapping to the matching Argument.
+// Assignments are keyed by their longest name, whichever of their names were given.
+func (c Commands) matchFlags(args arguments.Arguments, subIndex int, end int, ix flagIndex) (flagMap, error) {
+	var sub Commands
+	if subIndex >= 0 {
+		k, _ := c.findKey(args.CommandLine()[subIndex])
+		sub = c[k].(Commands)
+	}
 	m := flagMap{}
+	counts := map[interface{}]int{}
 	flags := args.Flags()
-	for _, arg := range flags {
-		k, ok := c.findKey(arg.Name)
+	// remove from the end, so the positions of the preceding flags remain valid
+	for i := len(flags) - 1; i >= 0; i-- {
+		arg := flags[i]
+		if arg.Position >= end {
+			continue
+		}
+		k, ok, err := c.findFlag(arg.Name)
+		if err != nil {
+			return nil, err
+		}
 		if !ok {
 			continue
 		}
-		arg.Parameters = c.trimParameters(c[k], arg.Parameters)
-		m[k] = arg
+		if sub != nil && arg.Position > subIndex {
+			if _, ok, _ := sub.findFlag(arg.Name); ok {
+				// child flags take precedence over the parent's
+				continue
+			}
+		}
+		if f := ix.flag(c, k); f != nil {
+			// assignments are grouped by their target, under their longest name, so the last of any alias is used
+			k = f.names[0]
+		}
+		// counters and flags without values may be marked on the Flag of any alias
+		cmd := ix.mapping(c, k)
+		if !arg.Assigned {
+			arg.Parameters = c.trimParameters(cmd, arg.Parameters)
+		}
+		if isCounter(cmd) {
+			counts[target(cmd)]++
+		}
 		if err := args.Remove(arg); err != nil {
-			log.Fatalln(err)
+			return nil, err
+		}
+		arg.Parameters = occurrenceParameters(cmd, arg)
+		if ma, ok := m[k]; !ok {
+			m[k] = arg
+		} else if isFlagValue(c[k]) {
+			// flag.Values are Set with every occurrence, in order
+			ma.Parameters = append(append([]string{}, arg.Parameters...), ma.Parameters...)
+		}
+	}
+	for k, arg := range m {
+		if cmd := ix.mapping(c, k); isCounter(cmd) {
+			// counted by target, so every alias counts
+			arg.Parameters = []string{strconv.Itoa(counts[target(cmd)])}
+		}
+	}
+	return m, nil
+}
+
+// occurrenceParameters gets the parameters set by the given flag argument, once removed from the command line.
+// Flags with a NoOptValue, given without a value, set that value, and bool flag.Values, given without a value, set "true".
+func occurrenceParameters(cmd interface{}, arg *arguments.Argument) []string {
+	if hasNoOptValue(cmd) && !arg.Assigned {
+		return []string{cmd.(Flag).NoOptValue}
+	}
+	if isBoolFlag(cmd) && len(arg.Parameters) == 0 {
+		return []string{"true"}
+	}
+	return arg.Parameters
+}
+
+// flagsEnd gets the position in the given arguments, at which flags are no longer matched.
+// When InterspersedFlags is set, this is the end of the arguments, otherwise, the first argument which is neither a flag nor a flag value.
+func (c Commands) flagsEnd(args []string, ix flagIndex) int {
+	if InterspersedFlags {
+		return len(args)
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == arguments.Terminator || !c.isFlag(arg) {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		k, ok, _ := c.findFlag(arg)
+		if !ok {
+			if flags, isCluster := c.splitCluster(arg, ix); isCluster {
+				// the last flag of a cluster takes its values
+				k, ok = c.findKey(flags[len(flags)-1])
+			}
+		}
+		if !ok {
+			continue
+		}
+		var params []string
+		for _, p := range args[i+1:] {
+			if c.isFlag(p) {
+				break
+			}
+			params = append(params, p)
+		}
+		i += len(c.trimParameters(ix.mapping(c, k), params))
+	}
+	return len(args)
+}
+
+// rawCommandIndex finds the position of the command in the given arguments, when it names a Raw Command in this map.
+// returns -1 if the command is not a raw command
+func (c Commands) rawCommandIndex(args []string, ix flagIndex) int {
+	i := c.commandPosition(args, ix)
+	if i < 0 {
+		return -1
+	}
+	k, ok := c.findKey(args[i])
+	if cm, isCmd := c[k].(Command); !ok || !isCmd || !cm.Raw {
+		return -1
+	}
+	return i
+}
+
+// commandPosition finds the position of the command in the given arguments, the first which is neither a flag nor a flag value.
+// Flags mapped in this map take the values they would be given when matched. Unknown flags are collected by any WildcardKey with their value,
+// otherwise an unknown flag preceding it leaves the command line without a command.
+// returns -1 if there is no command
+func (c Commands) commandPosition(args []string, ix flagIndex) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "" || arg == arguments.Terminator {
+			return -1
+		}
+		if !c.isFlag(arg) {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		k, ok, _ := c.findFlag(arg)
+		if !ok {
+			if flags, isCluster := c.splitCluster(arg, ix); isCluster {
+				// the last flag of a cluster takes its values
+				k, ok = c.findKey(flags[len(flags)-1])
+			}
+		}
+		var params []string
+		for _, p := range args[i+1:] {
+			if c.isFlag(p) {
+				break
+			}
+			params = append(params, p)
+		}
+		if !ok {
+			if _, wok := c[WildcardKey]; !wok {
+				return -1
+			}
+			if len(params) > 0 {
+				i++
+			}
+			continue
+		}
+		i += len(c.trimParameters(ix.mapping(c, k), params))
+	}
+	return -1
+}
+
+// subMapIndex finds the position of the command in the given arguments, when it names a sub map in this map.
+// returns -1 if the command is not a sub map
+func (c Commands) subMapIndex(args []string, ix flagIndex) int {
+	i := c.commandPosition(args, ix)
+	if i < 0 {
+		return -1
+	}
+	if k, ok := c.findKey(args[i]); !ok || !c.isSubmap(c[k]) {
+		return -1
+	}
+	return i
+}
+
+// expandClusters expands any clusters of single character flags, into their individual flags.
+// e.g. "-vqf" becomes "-v", "-q", "-f".
+// A cluster is expanded only when every character is a single character flag mapped in this map,
+// and all but the last are bool assignments or counters.  The last flag may take the parameters following the cluster.
+// Arguments following the terminator, or beyond the flags when InterspersedFlags is not set, are not expanded.
+func (c Commands) expandClusters(args []string, ix flagIndex) []string {
+	end := c.flagsEnd(args, ix)
+	var expanded []string
+	for i, arg := range args {
+		if i >= end || arg == arguments.Terminator {
+			return append(expanded, args[i:]...)
+		}
+		flags, ok := c.splitCluster(arg, ix)
+		if !ok {
+			expanded = append(expanded, arg)
+			continue
+		}
+		expanded = append(expanded, flags...)
+	}
+	return expanded
+}
+
+// splitCluster splits the given argument into its single character flags, if it is a cluster of mapped flags.
+func (c Commands) splitCluster(arg string, ix flagIndex) ([]string, bool) {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return nil, false
+	}
+	if _, ok := c.findKey(arg); ok {
+		return nil, false
+	}
+	chars := []rune(arg[1:])
+	flags := make([]string, len(chars))
+	for i, ch := range chars {
+		flags[i] = "-" + string(ch)
+		k, ok := c.findKey(flags[i])
+		if !ok {
+			return nil, false
+		}
+		if i < len(chars)-1 && !isCounter(ix.mapping(c, k)) && !(c.isAssignment(c[k]) && values.IsKind(target(c[k]), reflect.Bool)) {
+			return nil, false
 		}
 	}
-	return m
+	return flags, true
 }
 
-// findKey finds a key from an argumenet in a case insensitive search
+// findKey finds a key from an argumenet, matching the key exactly, or when both have the same Normalizer form.
 func (c Commands) findKey(arg string) (string, bool) {
+	if _, ok := c[arg]; ok {
+		return arg, true
+	}
+	if Normalizer == nil {
+		return "", false
+	}
+	n := Normalizer(arg)
 	for k := range c {
-		if strings.EqualFold(k, arg) {
+		if Normalizer(k) == n {
 			return k, true
 		}
 	}
 	return "", false
 }
 
+var nameReplacer = strings.NewReplacer("-", "", "_", "")
+
+// NormalizeName is the default Normalizer, folding the name to lower case and removing any dashes or underscores
+// following its leading dashes.  e.g. "--log_level", "--log-level" and "--LogLevel" all become "--loglevel"
+// Single character flags keep their case, so "-v" and "-V" remain different flags.
+func NormalizeName(name string) string {
+	body := strings.TrimLeft(name, "-")
+	prefix := name[:len(name)-len(body)]
+	if prefix != "" && len([]rune(body)) == 1 {
+		return name
+	}
+	return prefix + strings.ToLower(nameReplacer.Replace(body))
+}
+
 // trimParameters sets the number of parameters on the given slice to suit the intended target.
 // If cmd is an assignment (pointer to a variable/field) parameters are trimmed to a single one. (or none)
 // if cmd is a func, the func signature is checked and slice length is matched to the number of parameters.
 // Note functions using variadic parameters and sub commands are NOT trimmed.
 func (c Commands) trimParameters(cmd interface{}, parameters []string) []string {
+	if isCounter(cmd) || hasNoOptValue(cmd) || isBoolFlag(cmd) {
+		return parameters[:0]
+	}
+	cmd = target(cmd)
 	if c.isAssignment(cmd) {
 		if len(parameters) > 1 {
 			parameters = parameters[0:1]
@@ -201,6 +656,9 @@ func (c Commands) trimParameters(cmd interface{}, parameters []string) []string
 }
 
 func (c Commands) isAssignment(cmd interface{}) bool {
+	if _, ok := cmd.(Flag); ok {
+		return true
+	}
 	return reflect.TypeOf(cmd).Kind() == reflect.Ptr && !functions.IsFunc(cmd)
 }
 
@@ -209,14 +667,47 @@ func (c Commands) isSubmap(cmd interface{}) bool {
 	return ok
 }
 
-func (m flagMap) HelpCommand() interface{} {
-	cmd, ok := m[help.HelpFlagShort]
-	if ok {
-		return cmd
+// isFlag checks if the given argument is a flag.  Arguments which would be read as negative numbers, are flags when this map has them as keys.
+// e.g. "-4" is a flag when mapped, otherwise a value.
+func (c Commands) isFlag(arg string) bool {
+	if arguments.IsFlag(arg) {
+		return true
 	}
-	cmd, ok = m[help.HelpFlagFull]
-	if ok {
-		return cmd
+	if !strings.HasPrefix(arg, "-") {
+		return false
 	}
-	return nil
+	_, ok := c[strings.SplitN(arg, "=", 2)[0]]
+	return ok
+}
+
+// numericFlags gets the keys of this map which would otherwise be read as negative numbers, such as "-4".
+func (c Commands) numericFlags() []string {
+	var keys []string
+	for k := range c {
+		if strings.HasPrefix(k, "-") && !arguments.IsFlag(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// helpRequested checks if any of the given flags is mapped to help.HelpRequested, requesting help for this run.
+// The variable itself is never set, so each run has its own help state.
+func (c Commands) helpRequested(flags flagMap) bool {
+	for k := range flags {
+		if target(c[k]) == &help.HelpRequested {
+			return true
+		}
+	}
+	return false
+}
+
+// helpKey gets the key of the help flag in this flagMap, if present.
+func (m flagMap) helpKey() string {
+	for _, k := range []string{help.HelpFlagShort, help.HelpFlagFull} {
+		if _, ok := m[k]; ok {
+			return k
+		}
+	}
+	return ""
 }
@@ -0,0 +1,40 @@
+package commandgo
+
+import "testing"
+
+var (
+	benchVerbose bool
+	benchCount   int
+	benchName    string
+	benchForce   bool
+)
+
+var benchCommands = Commands{
+	"--verbose": &benchVerbose,
+	"-v":        &benchVerbose,
+	"--count":   &benchCount,
+	"--name":    &benchName,
+	"build":     func(target string, n int) error { return nil },
+	"deploy": Commands{
+		"--force": &benchForce,
+		"":        func(env string) {},
+	},
+}
+
+func BenchmarkRun(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchCommands.Run("build", "--verbose", "--count", "3", "target", "2"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRunSubMap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchCommands.Run("--name", "app", "deploy", "--force", "prod"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
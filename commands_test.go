@@ -0,0 +1,239 @@
+package commandgo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tagList is a flag.Value, appending every value it is set with.
+type tagList []string
+
+func (t *tagList) Set(s string) error {
+	*t = append(*t, s)
+	return nil
+}
+
+func (t *tagList) String() string {
+	return strings.Join(*t, ",")
+}
+
+// parsed is the state set by running the commands of newParseCommands
+type parsed struct {
+	Command string
+	Params  []string
+	Name    string
+	Count   int
+	Verbose int
+	Force   bool
+	Color   string
+	Tags    tagList
+	Env     string
+	IPv4    bool
+}
+
+func newParseCommands(p *parsed) Commands {
+	command := func(name string) func(args ...string) {
+		return func(args ...string) {
+			p.Command = name
+			if len(args) > 0 {
+				p.Params = args
+			}
+		}
+	}
+	return Commands{
+		"":          command("default"),
+		"build":     command("build"),
+		"-n":        &p.Name,
+		"--name":    &p.Name,
+		"--count":   &p.Count,
+		"-v":        Flag{Value: &p.Verbose, Counter: true},
+		"--verbose": &p.Verbose,
+		"-f":        &p.Force,
+		"--force":   &p.Force,
+		"--color":   Flag{Value: &p.Color, NoOptValue: "auto"},
+		"--tags":    &p.Tags,
+		"-t":        &p.Tags,
+		"-4":        &p.IPv4,
+		"deploy": Commands{
+			"--env": &p.Env,
+			"":      command("deploy"),
+		},
+	}
+}
+
+func TestRunParsesFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want parsed
+	}{
+		{name: "no arguments", want: parsed{Command: "default"}},
+		{name: "parameter", args: []string{"x"}, want: parsed{Command: "default", Params: []string{"x"}}},
+		{name: "flag", args: []string{"--name", "a"}, want: parsed{Command: "default", Name: "a"}},
+		{name: "assigned flag", args: []string{"--name=a", "x"}, want: parsed{Command: "default", Params: []string{"x"}, Name: "a"}},
+		{name: "last flag used", args: []string{"--name", "a", "--name", "b"}, want: parsed{Command: "default", Name: "b"}},
+		{name: "last alias used", args: []string{"-n", "first", "--name", "second"}, want: parsed{Command: "default", Name: "second"}},
+		{name: "last alias used reversed", args: []string{"--name", "first", "-n", "second"}, want: parsed{Command: "default", Name: "second"}},
+		{name: "normalized flag", args: []string{"--Name", "a"}, want: parsed{Command: "default", Name: "a"}},
+		{name: "negative number value", args: []string{"--count", "-3"}, want: parsed{Command: "default", Count: -3}},
+		{name: "negative number parameter", args: []string{"-3"}, want: parsed{Command: "default", Params: []string{"-3"}}},
+		{name: "numeric flag", args: []string{"-4", "x"}, want: parsed{Command: "default", Params: []string{"x"}, IPv4: true}},
+		{name: "numeric flag assigned", args: []string{"-4=false", "-3"}, want: parsed{Command: "default", Params: []string{"-3"}}},
+		{name: "numeric flag following value", args: []string{"--name", "a", "-4"}, want: parsed{Command: "default", Name: "a", IPv4: true}},
+		{name: "counter", args: []string{"-v", "-v"}, want: parsed{Command: "default", Verbose: 2}},
+		{name: "counter by alias", args: []string{"--verbose", "--verbose"}, want: parsed{Command: "default", Verbose: 2}},
+		{name: "counter by all aliases", args: []string{"-vvv", "--verbose"}, want: parsed{Command: "default", Verbose: 4}},
+		{name: "counter takes no parameter", args: []string{"--verbose", "x"}, want: parsed{Command: "default", Params: []string{"x"}, Verbose: 1}},
+		{name: "cluster", args: []string{"-fvv"}, want: parsed{Command: "default", Verbose: 2, Force: true}},
+		{name: "bool without value", args: []string{"-f", "x"}, want: parsed{Command: "default", Params: []string{"x"}, Force: true}},
+		{name: "bool with value", args: []string{"-f", "false", "x"}, want: parsed{Command: "default", Params: []string{"x"}}},
+		{name: "no opt value", args: []string{"--color", "x"}, want: parsed{Command: "default", Params: []string{"x"}, Color: "auto"}},
+		{name: "no opt value assigned", args: []string{"--color=never"}, want: parsed{Command: "default", Color: "never"}},
+		{name: "flag value every occurrence", args: []string{"--tags", "a", "-t", "b", "--tags=c"}, want: parsed{Command: "default", Tags: tagList{"a", "b", "c"}}},
+		{name: "command", args: []string{"build", "x", "y"}, want: parsed{Command: "build", Params: []string{"x", "y"}}},
+		{name: "flags around command", args: []string{"-n", "a", "build", "x", "--count", "2"}, want: parsed{Command: "build", Params: []string{"x"}, Name: "a", Count: 2}},
+		{name: "terminator", args: []string{"build", "--", "--count", "2"}, want: parsed{Command: "build", Params: []string{"--count", "2"}}},
+		{name: "command as parameter", args: []string{"x", "build"}, want: parsed{Command: "default", Params: []string{"x", "build"}}},
+		{name: "flag value named as command", args: []string{"--name", "build", "x"}, want: parsed{Command: "default", Params: []string{"x"}, Name: "build"}},
+		{name: "sub map", args: []string{"deploy", "--env", "prod", "x"}, want: parsed{Command: "deploy", Params: []string{"x"}, Env: "prod"}},
+		{name: "sub map with parent flags", args: []string{"-n", "a", "deploy", "--count", "2", "x"}, want: parsed{Command: "deploy", Params: []string{"x"}, Name: "a", Count: 2}},
+		{name: "sub map named as parameter", args: []string{"build", "deploy"}, want: parsed{Command: "build", Params: []string{"deploy"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got parsed
+			if _, err := newParseCommands(&got).Run(tt.args...); err != nil {
+				t.Fatalf("Run(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunParsesFlagsNotInterspersed(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want parsed
+	}{
+		{name: "flags before command", args: []string{"-n", "a", "build", "x"}, want: parsed{Command: "build", Params: []string{"x"}, Name: "a"}},
+		{name: "flags after command", args: []string{"build", "x", "--count", "2"}, want: parsed{Command: "build", Params: []string{"x", "--count", "2"}}},
+		{name: "cluster before command", args: []string{"-fv", "build"}, want: parsed{Command: "build", Verbose: 1, Force: true}},
+		{name: "cluster taking value", args: []string{"-fn", "a", "build"}, want: parsed{Command: "build", Name: "a", Force: true}},
+		{name: "cluster after command", args: []string{"build", "prog", "-fv", "--x=1"}, want: parsed{Command: "build", Params: []string{"prog", "-fv", "--x=1"}}},
+	}
+	defer func(b bool) { InterspersedFlags = b }(InterspersedFlags)
+	InterspersedFlags = false
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got parsed
+			if _, err := newParseCommands(&got).Run(tt.args...); err != nil {
+				t.Fatalf("Run(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunChecksFlagsBeforeFlagFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "required given", args: []string{"--init", "--a", "1"}},
+		{name: "required missing", args: []string{"--init"}, wantErr: true},
+		{name: "excluded given", args: []string{"--init", "--a", "1", "--x", "2", "--y", "3"}, wantErr: true},
+		{name: "excluded by alias", args: []string{"--init", "--a", "1", "-x", "2", "--y", "3"}, wantErr: true},
+		{name: "requirement missing", args: []string{"--init", "--a", "1", "--z", "3"}, wantErr: true},
+		{name: "requirement given", args: []string{"--init", "--a", "1", "--z", "3", "-x", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, x, y, z string
+			var invoked bool
+			c := Commands{
+				"":       func() {},
+				"--init": func() { invoked = true },
+				"--a":    Flag{Value: &a, Required: true},
+				"--x":    Flag{Value: &x, Excludes: []string{"--y"}},
+				"-x":     &x,
+				"--y":    &y,
+				"--z":    Flag{Value: &z, Requires: []string{"--x"}},
+			}
+			_, err := c.Run(tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+			if invoked == tt.wantErr {
+				t.Errorf("Run(%q) invoked --init = %v, want %v", tt.args, invoked, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunResetsFlags(t *testing.T) {
+	var tags tagList
+	var name string
+	var count int
+	c := Commands{
+		"":        func() {},
+		"--tags":  Flag{Value: &tags, Default: "x"},
+		"--name":  &name,
+		"--count": Flag{Value: &count, Default: "1"},
+	}
+	runs := []struct {
+		args  []string
+		set   string
+		tags  tagList
+		name  string
+		count int
+	}{
+		{args: []string{"--tags", "b", "--name", "a", "--count", "2"}, tags: tagList{"x", "b"}, name: "a", count: 2},
+		{args: []string{"--tags", "b"}, tags: tagList{"x", "b"}, count: 1},
+		{args: []string{"--tags", "b", "--tags", "c"}, tags: tagList{"x", "b", "c"}, count: 1},
+		{tags: tagList{"x"}, count: 1},
+		{args: []string{"--name", "a"}, tags: tagList{"x"}, name: "a", count: 1},
+		{set: "b", tags: tagList{"x"}, name: "b", count: 1},
+	}
+	for i, r := range runs {
+		if r.set != "" {
+			// set by the program between runs
+			name = r.set
+		}
+		if _, err := c.Run(r.args...); err != nil {
+			t.Fatalf("run %d Run(%q) error = %v", i, r.args, err)
+		}
+		if !reflect.DeepEqual(tags, r.tags) || name != r.name || count != r.count {
+			t.Errorf("run %d Run(%q) set %v, %q, %d, want %v, %q, %d", i, r.args, tags, name, count, r.tags, r.name, r.count)
+		}
+	}
+}
+
+func TestRunAppliesConfigSections(t *testing.T) {
+	defer func(f string) { ConfigFile = f }(ConfigFile)
+	ConfigFile = filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(ConfigFile, []byte(`{"build_all": {"jobs": 4}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var jobs int
+	c := Commands{
+		"build-all": Commands{
+			"":       func() {},
+			"--jobs": &jobs,
+		},
+	}
+	if _, err := c.Run("build-all"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if jobs != 4 {
+		t.Errorf("Run() set jobs %d, want 4", jobs)
+	}
+}
@@ -0,0 +1,201 @@
+package commandgo
+
+import (
+	"commandgo/functions"
+	"commandgo/values"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Compile validates the complete mapping of this commands, including all its sub maps, returning every problem found.
+// Checks all mappings are of a supported type, all assignments and func parameters are of types which can be parsed,
+// raw commands take a []string parameter, no two keys in the same map have the same Normalizer form and no flags shadow each other.
+// When valid, returns a copy of this commands, including copies of its sub maps, with the help flags in place.
+// The copy is unaffected by any later changes to this commands, and is not modified when run.
+func (c Commands) Compile() (Commands, error) {
+	var errs Errors
+	cc := c.compile("", &errs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cc, nil
+}
+
+func (c Commands) compile(path string, errs *Errors) Commands {
+	cc := Commands{}
+	for _, k := range c.sortedKeys() {
+		kp := strings.TrimSpace(strings.Join([]string{path, k}, " "))
+		if kp == "" {
+			kp = "default"
+		}
+		if dk, ok := cc.findKey(k); ok {
+			*errs = append(*errs, fmt.Errorf("%q is a duplicate of %q", kp, dk))
+		}
+		cmd := c[k]
+		if sc, ok := cmd.(Commands); ok {
+			cc[k] = sc.compile(kp, errs)
+			continue
+		}
+		if k == WildcardKey {
+			if !isWildcard(cmd) {
+				*errs = append(*errs, fmt.Errorf("%q must be mapped to a pointer to a map with string keys", kp))
+			}
+			cc[k] = cmd
+			continue
+		}
+		if err := checkMapping(cmd); err != nil {
+			*errs = append(*errs, fmt.Errorf("%q %v", kp, err))
+		}
+		if fl, ok := cmd.(Flag); ok {
+			for _, n := range append(append([]string{}, fl.Excludes...), fl.Requires...) {
+				if _, ok := c.findKey(n); !ok {
+					*errs = append(*errs, fmt.Errorf("%q relates to %s, which is not a known flag", kp, n))
+				}
+			}
+			if fl.When != "" {
+				if _, err := c.conditionMet(fl.When); err != nil {
+					*errs = append(*errs, fmt.Errorf("%q %v", kp, err))
+				}
+			}
+		}
+		cc[k] = cmd
+	}
+	*errs = append(*errs, c.checkShadows(path)...)
+	return cc.withHelpFlags()
+}
+
+// checkShadows checks for flags in this map which shadow one another.
+// Flags named the same, other than their leading dashes, must map the same value. e.g. "-verbose" and "--verbose"
+// A single dash flag of more than one letter must not be named as a cluster of single letter flags. e.g. "-vq" with "-v" and "-q"
+func (c Commands) checkShadows(path string) []error {
+	var errs []error
+	names := map[string]string{}
+	for _, k := range c.sortedKeys() {
+		if !strings.HasPrefix(k, "-") || !c.isAssignment(c[k]) {
+			continue
+		}
+		kp := strings.TrimSpace(strings.Join([]string{path, k}, " "))
+		n := k
+		if Normalizer != nil {
+			n = Normalizer(k)
+		}
+		n = strings.TrimLeft(n, "-")
+		if sk, ok := names[n]; ok && !sameMapping(c[sk], c[k]) {
+			errs = append(errs, fmt.Errorf("%q shadows %q", kp, sk))
+		}
+		names[n] = k
+		if cluster := c.clusterKeys(k); len(cluster) > 0 {
+			errs = append(errs, fmt.Errorf("%q shadows the flags %s", kp, strings.Join(cluster, ", ")))
+		}
+	}
+	return errs
+}
+
+// clusterKeys gets the keys of the single letter flags, which the given single dash flag could be a cluster of.
+// Gives nil if the key is not a single dash flag of more than one letter, or any of its letters is not a flag in this map.
+func (c Commands) clusterKeys(key string) []string {
+	if len(key) < 3 || key[0] != '-' || key[1] == '-' {
+		return nil
+	}
+	var keys []string
+	for _, ch := range key[1:] {
+		k, ok := c.findKey("-" + string(ch))
+		if !ok || !c.isAssignment(c[k]) {
+			return nil
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// sortedKeys gets the keys of this commands in order, for consistent reporting.
+func (c Commands) sortedKeys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkMapping checks the given mapping can be invoked.
+func checkMapping(cmd interface{}) error {
+	if cmd == nil {
+		return fmt.Errorf("is mapped to nil")
+	}
+	if cm, ok := cmd.(Command); ok {
+		if !functions.IsFunc(cm.Func) {
+			return fmt.Errorf("command is mapped to an unknown type %T", cm.Func)
+		}
+		sig := functions.NewSignature(cm.Func)
+		if cm.UnknownFlags != nil && !isWildcard(cm.UnknownFlags) {
+			return fmt.Errorf("UnknownFlags must be a pointer to a map with string keys")
+		}
+		if cm.Raw {
+			if len(sig.ParamTypes) != 1 || sig.ParamTypes[0] != reflect.TypeOf([]string{}) {
+				return fmt.Errorf("is a raw command and must have a single []string parameter")
+			}
+			return nil
+		}
+		if cm.StdinParameter > len(sig.ParamTypes) {
+			return fmt.Errorf("stdin parameter %d is out of range, func has %d parameters", cm.StdinParameter, len(sig.ParamTypes))
+		}
+		return checkSignature(sig)
+	}
+	if functions.IsFunc(cmd) {
+		return checkSignature(functions.NewSignature(cmd))
+	}
+	if fl, ok := cmd.(Flag); ok {
+		if fl.Value == nil || reflect.TypeOf(fl.Value).Kind() != reflect.Ptr || functions.IsFunc(fl.Value) {
+			return fmt.Errorf("is a flag and must have a pointer to a variable or field as its value")
+		}
+		if fl.Counter && !values.IsKind(fl.Value, reflect.Int) {
+			return fmt.Errorf("is a counter and must have a pointer to an int as its value")
+		}
+		if fl.Default != "" {
+			if _, err := values.ValueFromString(fl.Default, reflect.TypeOf(fl.Value).Elem()); err != nil {
+				return fmt.Errorf("has an invalid default  %v", err)
+			}
+			if err := checkChoice(fl, fl.Default); err != nil {
+				return fmt.Errorf("has an invalid default  %v", err)
+			}
+		}
+		if fl.NoOptValue != "" {
+			if _, err := values.ValueFromString(fl.NoOptValue, reflect.TypeOf(fl.Value).Elem()); err != nil {
+				return fmt.Errorf("has an invalid NoOptValue  %v", err)
+			}
+		}
+		for _, ch := range fl.Choices {
+			if _, err := values.ValueFromString(ch, reflect.TypeOf(fl.Value).Elem()); err != nil {
+				return fmt.Errorf("has an invalid choice  %v", err)
+			}
+		}
+		cmd = fl.Value
+	}
+	if reflect.TypeOf(cmd).Kind() == reflect.Ptr {
+		if t := reflect.TypeOf(cmd).Elem(); !values.IsSupported(t) {
+			return fmt.Errorf("is mapped to a %s, which can not be parsed from the command line", t.String())
+		}
+		return nil
+	}
+	return fmt.Errorf("is mapped to an unknown type %T", cmd)
+}
+
+// checkSignature checks all the parameters of the given signature can be parsed.
+func checkSignature(sig *functions.Signature) error {
+	var bad []string
+	for i, pt := range sig.ParamTypes {
+		if sig.IsVariadic && i == len(sig.ParamTypes)-1 {
+			pt = pt.Elem()
+		}
+		if !values.IsSupported(pt) {
+			bad = append(bad, fmt.Sprintf("%d (%s)", i+1, pt.String()))
+		}
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("has parameters which can not be parsed from the command line: %s", strings.Join(bad, ", "))
+	}
+	return nil
+}
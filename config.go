@@ -0,0 +1,105 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/config"
+	"commandgo/help"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConfigFile, when set, is the path of a json configuration file, giving values for flags not given on the command line.
+// Keys in the file are the flag names, without their leading dashes, matched as they are on the command line, using the Normalizer.
+// Sub commands take their flags from an object named after them.
+// e.g. {"verbose": true, "get": {"headers": true}}
+// The file is optional and ignored when it does not exist. Any keys which are not known flags fail the command.
+// Values are applied in the order of precedence: command line, environment, config file.
+var ConfigFile string
+
+// loadConfig loads the ConfigFile, if one is set and it exists.
+func loadConfig() (config.Section, error) {
+	if ConfigFile == "" {
+		return nil, nil
+	}
+	cfg, err := config.Load(ConfigFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configFlags adds any flags not in the given flags, which have a value in the given config section.
+// returns an error listing all the keys in the section which are neither flags nor sub commands of this map.
+func (c Commands) configFlags(flags flagMap, cfg config.Section, ix flagIndex) error {
+	var errs Errors
+	for _, key := range sectionKeys(cfg) {
+		k, ok := c.findFlagName(key)
+		if !ok {
+			if sk, ok := c.findKey(key); ok && c.isSubmap(c[sk]) && cfg.IsSection(key) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%q in config file %s is not a known flag", key, ConfigFile))
+			continue
+		}
+		names := ix.flag(c, k).names
+		if flags.containsAny(names) {
+			continue
+		}
+		v, ok := cfg.Value(key)
+		if !ok {
+			continue
+		}
+		flags[names[0]] = &arguments.Argument{Name: names[0], Position: -1, Parameters: []string{v}}
+	}
+	return errs.errorOrNil()
+}
+
+// configSection gets the section of the given config for the sub command of the given key.
+// Sections are named as the command, or with the same Normalizer form, as configFlags accepts them.
+func (c Commands) configSection(cfg config.Section, key string) config.Section {
+	if cfg.IsSection(key) {
+		return cfg.Section(key)
+	}
+	for _, sk := range sectionKeys(cfg) {
+		if k, ok := c.findKey(sk); ok && k == key && cfg.IsSection(sk) {
+			return cfg.Section(sk)
+		}
+	}
+	return nil
+}
+
+// sectionKeys gets the keys of the given section, sorted.
+func sectionKeys(cfg config.Section) []string {
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// findFlagName finds the key of an assignment in this map, named with the given name, ignoring any leading dashes.
+// Names match exactly, or when both have the same Normalizer form.
+func (c Commands) findFlagName(name string) (string, bool) {
+	var found string
+	for _, k := range c.sortedKeys() {
+		cmd := c[k]
+		if !c.isAssignment(cmd) || target(cmd) == &help.HelpRequested {
+			continue
+		}
+		kn := strings.TrimLeft(k, "-")
+		if kn == name {
+			return k, true
+		}
+		// compared with the same leading dashes as the key
+		if Normalizer != nil && found == "" && Normalizer(k[:len(k)-len(kn)]+name) == Normalizer(k) {
+			found = k
+		}
+	}
+	return found, found != ""
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"commandgo/values"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Section is a set of configuration values, keyed by the flag names they set, without their leading dashes.
+// A value which is itself an object is the Section for the sub command of that name.
+// e.g. {"verbose": true, "get": {"headers": true}} sets the verbose flag, and the headers flag of the get sub command.
+type Section map[string]interface{}
+
+// Load reads the configuration file at the given path.
+// A leading "~/" in the path is replaced with the user's home directory.
+// Only json files are supported.
+func Load(path string) (Section, error) {
+	p, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".yaml", ".yml", ".toml":
+		return nil, fmt.Errorf("config file %s is not supported, config files must be json", path)
+	}
+	by, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var s Section
+	dec := json.NewDecoder(bytes.NewReader(by))
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("config file %s could not be read  %v", path, err)
+	}
+	return s, nil
+}
+
+// Section gets the sub section of the given name. Returns nil if not found or the named value is not a section.
+func (s Section) Section(name string) Section {
+	m, ok := s[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return m
+}
+
+// IsSection checks if the given key names a sub section.
+func (s Section) IsSection(key string) bool {
+	_, ok := s[key].(map[string]interface{})
+	return ok
+}
+
+// Value gets the named value as a string, in the form parsed by values.ValueFromString.
+// Arrays are joined with the values.SliceDelimiter, objects are given as json.
+// Returns false if the name is not found or is null.
+func (s Section) Value(name string) (string, bool) {
+	v, ok := s[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	return valueString(v), true
+}
+
+func valueString(v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case []interface{}:
+		items := make([]string, len(vv))
+		for i, item := range vv {
+			items[i] = valueString(item)
+		}
+		return strings.Join(items, values.SliceDelimiter)
+	case map[string]interface{}:
+		by, err := json.Marshal(vv)
+		if err != nil {
+			return ""
+		}
+		return string(by)
+	default:
+		return fmt.Sprint(vv)
+	}
+}
+
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,54 @@
+package commandgo
+
+import (
+	"errors"
+	"strings"
+)
+
+// HintError is an error carrying a hint to the user on how it may be resolved.
+type HintError struct {
+	Err  error
+	Hint string
+}
+
+func (e HintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e HintError) Unwrap() error {
+	return e.Err
+}
+
+// WithHint wraps the given error with the given hint to the user.
+func WithHint(err error, hint string) error {
+	return HintError{Err: err, Hint: hint}
+}
+
+// Hint gets the hint of the given error, or of any error it wraps.
+// returns empty if the error has no hint.
+func Hint(err error) string {
+	var he HintError
+	if errors.As(err, &he) {
+		return he.Hint
+	}
+	return ""
+}
+
+// Errors is a collection of errors, reported together as a single error.
+type Errors []error
+
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// errorOrNil returns the given errors as an error, or nil when there are none.
+func (e Errors) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
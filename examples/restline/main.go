@@ -79,6 +79,9 @@ func main() {
 	// Call using the os.CommandLine argument
 	r, err := cmds.RunArgs()
 	if err != nil {
+		if hint := commandgo.Hint(err); hint != "" {
+			log.Fatalf("%v\n%s", err, hint)
+		}
 		log.Fatalln(err)
 	}
 
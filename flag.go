@@ -0,0 +1,648 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/help"
+	"commandgo/values"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EnvPrefix, when set, reads any flag not given on the command line from an environment variable.
+// The variable name is the prefix, followed by an underscore and the longest name of the flag, in upper case,
+// with dashes replaced by underscores. e.g. with an EnvPrefix of "MYAPP", "--output" is read from MYAPP_OUTPUT
+var EnvPrefix string
+
+// AbbreviatedFlags, when true, allows long flags, those beginning with "--", to be given by any unique prefix of their name.
+// e.g. "--verb" for "--verbose".  A prefix matching more than one flag fails as ambiguous.
+var AbbreviatedFlags bool
+
+// FileValuePrefix, when set, marks a flag value on the command line, as the path of a file to read the value from.
+// e.g. with a prefix of "@", "--config @settings.json" sets the config flag to the contents of settings.json.  "@-" reads the value from stdin.
+// Trailing line breaks are removed from the file.  Empty by default, reading all values as given.
+// When arguments.ResponseFiles is set, these values must follow an '=' in the flag argument. e.g. "--config=@settings.json"
+var FileValuePrefix string
+
+// Flag wraps an assignment mapping (a pointer to a variable or field) with additional options.
+// A Flag may be used as a mapping value in place of the pointer itself.
+// e.g. "--output": commandgo.Flag{Value: &Output, Required: true}
+// Aliases of the flag may map to the same Flag or to the pointer alone.
+type Flag struct {
+	// Value is the pointer to the variable or field assigned by the flag.
+	Value interface{}
+
+	// Required, when true, fails the command when the flag, or any of its aliases, is not given.
+	Required bool
+
+	// Env, when set, names an environment variable the flag is read from, when not given on the command line.
+	Env string
+
+	// Usage describes the flag to the user.
+	Usage string
+
+	// Placeholder names the value of the flag in usage listings. e.g. "file" in "--output <file>"
+	// Defaults to the type of the value.
+	Placeholder string
+
+	// Hidden, when true, excludes the flag from any listing of flags.
+	Hidden bool
+
+	// Default, when set, is the value the flag is reset to, before each run of its Commands, and by Reset.
+	// Given in the same form as on the command line.  Flags without a Default, set by a run, are restored to the value
+	// their variable held before that run, unless the variable has been changed since.
+	Default string
+
+	// Excludes names the flags which may not be given with this flag. e.g. "--json" excluding "--yaml"
+	Excludes []string
+
+	// Requires names the flags which must also be given, whenever this flag is given. e.g. "--user" requiring "--password"
+	Requires []string
+
+	// When names another flag, and optionally its value, which must be set for this flag to be used.
+	// e.g. "--tls", when the tls flag must have a non zero value, or "--mode=server", when mode must be "server".
+	When string
+
+	// Choices, when set, are the only values the flag may be given. e.g. "json", "yaml", "table"
+	Choices []string
+
+	// Validate, when set, checks the value of the flag, once it has been set from the command line.
+	// It is given the value of the variable, not the pointer. e.g. an int for a *int Value
+	Validate func(v interface{}) error
+
+	// NoOptValue, when set, is the value given to the flag when it appears without an '=' and a value.
+	// e.g. with a NoOptValue of "auto", "--color" sets "auto" and "--color=never" sets "never".
+	// The flag never takes the following argument as its value.
+	NoOptValue string
+
+	// Secret, when true, marks the value as one which should not be shown, such as a password or token.
+	// When not given, and stdin is a terminal, the value is prompted for, with the input hidden.
+	// Secret values are not shown in usage listings, or given by Args.
+	Secret bool
+
+	// Counter, when true, counts the number of times the flag appears, taking no parameter.
+	// The Value must be an int, which is set to the count. e.g. "-v -v -v" or "-vvv" sets a count of 3.
+	Counter bool
+}
+
+// Validator may be implemented by the type of a flag's variable, to check its value once set from the command line.
+// e.g. a Port type with a Validate method, checking it is in the range 1-65535
+type Validator interface {
+	Validate() error
+}
+
+// isCounter checks if the given mapping is a counter Flag
+func isCounter(cmd interface{}) bool {
+	fl, ok := cmd.(Flag)
+	return ok && fl.Counter
+}
+
+// hasNoOptValue checks if the given mapping is a Flag with a NoOptValue
+func hasNoOptValue(cmd interface{}) bool {
+	fl, ok := cmd.(Flag)
+	return ok && fl.NoOptValue != ""
+}
+
+// isFlagValue checks if the given mapping targets a standard flag.Value, which is Set with every occurrence of its flag.
+func isFlagValue(cmd interface{}) bool {
+	_, ok := target(cmd).(flag.Value)
+	return ok
+}
+
+// isBoolFlag checks if the given mapping targets a flag.Value with an IsBoolFlag method returning true, taking no parameter.
+func isBoolFlag(cmd interface{}) bool {
+	bf, ok := target(cmd).(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// target gets the pointer or func a mapping targets, unwrapping any Flag or Command.
+func target(cmd interface{}) interface{} {
+	switch m := cmd.(type) {
+	case Flag:
+		return m.Value
+	case Command:
+		return m.Func
+	default:
+		return cmd
+	}
+}
+
+// flagIndex indexes the assignments of a map by the pointer they target, so the names and options of a flag are found without searching the map.
+type flagIndex map[interface{}]*indexedFlag
+
+// indexedFlag is a flag, with all the keys mapping its pointer, longest first, and the Flags mapped by those keys, in the same order.
+// Its options are those of all its Flags combined.
+type indexedFlag struct {
+	value   interface{}
+	names   []string
+	flags   []Flag
+	options Flag
+}
+
+// flagIndex indexes all the assignments in this map by their target.
+func (c Commands) flagIndex() flagIndex {
+	ix := make(flagIndex, len(c))
+	entries := make([]indexedFlag, 0, len(c))
+	for k, cmd := range c {
+		if !c.isAssignment(cmd) {
+			continue
+		}
+		t := target(cmd)
+		f, ok := ix[t]
+		if !ok {
+			entries = append(entries, indexedFlag{value: t})
+			f = &entries[len(entries)-1]
+			ix[t] = f
+		}
+		f.names = append(f.names, k)
+	}
+	for _, f := range ix {
+		sortNames(f.names)
+		for _, n := range f.names {
+			if fl, ok := c[n].(Flag); ok {
+				f.flags = append(f.flags, fl)
+			}
+		}
+		f.options = mergeFlags(f.value, f.flags)
+	}
+	return ix
+}
+
+// flag gets the indexed flag of the given key in the given map, or nil when the key is not an assignment.
+func (ix flagIndex) flag(c Commands, key string) *indexedFlag {
+	if cmd, ok := c[key]; !ok || !c.isAssignment(cmd) {
+		return nil
+	}
+	return ix[target(c[key])]
+}
+
+// mapping gets the mapping of the given key in the given map, as a Flag with the options of all its aliases, when it is an assignment.
+func (ix flagIndex) mapping(c Commands, key string) interface{} {
+	if f := ix.flag(c, key); f != nil {
+		return f.options
+	}
+	return c[key]
+}
+
+// mergeFlags combines the options of the given Flags, all mapping the given value.
+// Each option is taken from the first Flag to set it, the Excludes and Requires of them all are combined.
+func mergeFlags(value interface{}, fls []Flag) Flag {
+	m := Flag{Value: value}
+	if len(fls) == 1 {
+		m = fls[0]
+		m.Value = value
+		return m
+	}
+	for _, fl := range fls {
+		m.Required = m.Required || fl.Required
+		m.Hidden = m.Hidden || fl.Hidden
+		m.Secret = m.Secret || fl.Secret
+		m.Counter = m.Counter || fl.Counter
+		m.Excludes = appendUnique(m.Excludes, fl.Excludes...)
+		m.Requires = appendUnique(m.Requires, fl.Requires...)
+		if m.Env == "" {
+			m.Env = fl.Env
+		}
+		if m.Usage == "" {
+			m.Usage = fl.Usage
+		}
+		if m.Placeholder == "" {
+			m.Placeholder = fl.Placeholder
+		}
+		if m.Default == "" {
+			m.Default = fl.Default
+		}
+		if m.When == "" {
+			m.When = fl.When
+		}
+		if m.NoOptValue == "" {
+			m.NoOptValue = fl.NoOptValue
+		}
+		if len(m.Choices) == 0 {
+			m.Choices = fl.Choices
+		}
+		if m.Validate == nil {
+			m.Validate = fl.Validate
+		}
+	}
+	return m
+}
+
+// sortNames sorts the names of a flag, longest first.
+func sortNames(names []string) {
+	// flags have few names, sorted in place
+	for i := 1; i < len(names); i++ {
+		for j := i; j > 0 && longerName(names[j], names[j-1]); j-- {
+			names[j], names[j-1] = names[j-1], names[j]
+		}
+	}
+}
+
+// longerName checks if the given name a is ordered before b, being longer, or the same length and lower.
+func longerName(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a < b
+}
+
+// aliases gets all the keys in this map, mapped to the same target as the given key, including the key itself.
+func (c Commands) aliases(key string) []string {
+	t := target(c[key])
+	var names []string
+	for k, cmd := range c {
+		if k == key || (c.isAssignment(cmd) && target(cmd) == t) {
+			names = append(names, k)
+		}
+	}
+	sortNames(names)
+	return names
+}
+
+// checkRequired checks all the Required flags in this map, or one of their aliases, are in the given flags.
+// returns an error listing every missing flag.
+func (c Commands) checkRequired(flags flagMap, ix flagIndex) error {
+	var missing, hints []string
+	for _, f := range ix {
+		if !f.options.Required || flags.containsAny(f.names) {
+			continue
+		}
+		missing = append(missing, formatNames(f.names))
+		if en := f.envName(); en != "" {
+			hints = append(hints, fmt.Sprintf("set %s or the %s environment variable", f.names[0], en))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	sort.Strings(hints)
+	err := fmt.Errorf("missing required flags %s", strings.Join(missing, ", "))
+	if len(missing) == 1 {
+		err = fmt.Errorf("missing required flag %s", missing[0])
+	}
+	if len(hints) > 0 {
+		return WithHint(err, strings.Join(hints, "\n"))
+	}
+	return err
+}
+
+// findFlag finds the key of the given flag name, or of the flag it abbreviates, when AbbreviatedFlags is set.
+// returns false if not found, or an error if an abbreviation matches more than one flag.
+func (c Commands) findFlag(name string) (string, bool, error) {
+	if k, ok := c.findKey(name); ok || !AbbreviatedFlags || !strings.HasPrefix(name, "--") {
+		return k, ok, nil
+	}
+	norm := Normalizer
+	if norm == nil {
+		norm = func(s string) string { return s }
+	}
+	prefix := norm(name)
+	var found []string
+	done := map[interface{}]bool{}
+	for _, k := range c.sortedKeys() {
+		if !strings.HasPrefix(k, "--") || !strings.HasPrefix(norm(k), prefix) {
+			continue
+		}
+		if c.isAssignment(c[k]) {
+			// aliases of the same flag are not ambiguous
+			if done[target(c[k])] {
+				continue
+			}
+			done[target(c[k])] = true
+		}
+		found = append(found, k)
+	}
+	switch len(found) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return found[0], true, nil
+	default:
+		return "", false, fmt.Errorf("%s is ambiguous, it could be any of %s", name, strings.Join(found, ", "))
+	}
+}
+
+// checkChoice checks the given value of the given flag mapping is one of its Choices, if it has any.
+func checkChoice(cmd interface{}, value string) error {
+	fl, ok := cmd.(Flag)
+	if !ok || len(fl.Choices) == 0 {
+		return nil
+	}
+	for _, ch := range fl.Choices {
+		if ch == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not a valid choice, must be one of %s", value, strings.Join(fl.Choices, ", "))
+}
+
+// validate checks the value the given pointer points to, with the Validate funcs of the given Flags, and the Validator of its type, if any.
+func validate(p interface{}, fls []Flag) error {
+	for _, fl := range fls {
+		if fl.Validate == nil {
+			continue
+		}
+		if err := fl.Validate(reflect.ValueOf(p).Elem().Interface()); err != nil {
+			return err
+		}
+	}
+	if v, ok := p.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// checkRelated checks the Excludes, Requires and When of all the flags, in the given flags, are met.
+// The options are taken from the Flags mapped by the flag and all its aliases.
+// returns an error for every flag given with an excluded flag or without a flag it requires.
+func (c Commands) checkRelated(flags flagMap, ix flagIndex) error {
+	var given []*indexedFlag
+	for _, f := range ix {
+		if flags.containsAny(f.names) {
+			given = append(given, f)
+		}
+	}
+	// checked in the order of their names, for consistent reporting
+	sort.Slice(given, func(i, j int) bool {
+		return given[i].names[0] < given[j].names[0]
+	})
+	var errs Errors
+	for _, f := range given {
+		k := f.names[0]
+		for _, ex := range f.options.Excludes {
+			if flags.containsAny(c.relatedNames(ex, ix)) {
+				errs = append(errs, fmt.Errorf("%s can not be used with %s", k, ex))
+			}
+		}
+		var missing []string
+		for _, rq := range f.options.Requires {
+			if !flags.containsAny(c.relatedNames(rq, ix)) {
+				missing = append(missing, rq)
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s requires %s", k, strings.Join(missing, ", ")))
+		}
+		var whens []string
+		for _, fl := range f.flags {
+			if fl.When != "" {
+				whens = appendUnique(whens, fl.When)
+			}
+		}
+		for _, when := range whens {
+			ok, err := c.conditionMet(when)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %v", k, err))
+			} else if !ok {
+				errs = append(errs, fmt.Errorf("%s can only be used with %s", k, when))
+			}
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// appendUnique appends the given strings, not already in the given slice.
+func appendUnique(s []string, strs ...string) []string {
+	for _, str := range strs {
+		found := false
+		for _, e := range s {
+			if e == str {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, str)
+		}
+	}
+	return s
+}
+
+// conditionMet checks if the flag named in the given condition has the value given in the condition.
+// The condition is a flag name, optionally followed by '=' and a value. e.g. "--mode=server".
+// Without a value, the condition is met when the flag has a non zero value.
+func (c Commands) conditionMet(condition string) (bool, error) {
+	parts := strings.SplitN(condition, "=", 2)
+	k, ok := c.findKey(parts[0])
+	if !ok || !c.isAssignment(c[k]) {
+		return false, fmt.Errorf("condition %s is not a known flag", condition)
+	}
+	v := reflect.ValueOf(target(c[k])).Elem()
+	if len(parts) == 1 {
+		return !v.IsZero(), nil
+	}
+	cv, err := values.ValueFromString(parts[1], v.Type())
+	if err != nil {
+		return false, fmt.Errorf("condition %s is invalid  %v", condition, err)
+	}
+	return reflect.DeepEqual(v.Interface(), cv), nil
+}
+
+// relatedNames gets all the names of the flag named by a Flag's Excludes or Requires.
+func (c Commands) relatedNames(name string, ix flagIndex) []string {
+	k, ok := c.findKey(name)
+	if !ok {
+		return []string{name}
+	}
+	if f := ix.flag(c, k); f != nil {
+		return f.names
+	}
+	return []string{k}
+}
+
+// formatNames formats the names of a flag, the first name followed by any aliases in brackets.
+func formatNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return fmt.Sprintf("%s (%s)", names[0], strings.Join(names[1:], ", "))
+}
+
+func (m flagMap) containsAny(keys []string) bool {
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentFlags adds any flags not in the given flags which have a value in the environment.
+// Flags are read from their Env variable or, when an EnvPrefix is set, the variable named from the flag.
+// Empty variables are ignored.
+func (c Commands) environmentFlags(flags flagMap, ix flagIndex) {
+	for t, f := range ix {
+		if t == &help.HelpRequested || flags.containsAny(f.names) {
+			continue
+		}
+		en := f.envName()
+		if en == "" {
+			continue
+		}
+		v := os.Getenv(en)
+		if v == "" {
+			continue
+		}
+		flags[f.names[0]] = &arguments.Argument{Name: f.names[0], Position: -1, Parameters: []string{v}}
+	}
+}
+
+// envName gets the environment variable name for the flag
+// returns empty if the flag has no Env and no EnvPrefix is set.
+func (f *indexedFlag) envName() string {
+	if f.options.Env != "" {
+		return f.options.Env
+	}
+	if EnvPrefix == "" || !strings.HasPrefix(f.names[0], "-") {
+		return ""
+	}
+	n := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(strings.TrimLeft(f.names[0], "-")))
+	return strings.Join([]string{strings.TrimSuffix(EnvPrefix, "_"), n}, "_")
+}
+
+// Reset restores all the flags in this map, and its sub maps, to their Default, or when they have none,
+// to the value their variable held before a run set it, unless the variable has been changed since.
+// Returns an error listing any defaults which could not be parsed.
+func (c Commands) Reset() error {
+	var errs Errors
+	var defaults []string
+	for k, cmd := range c {
+		switch m := cmd.(type) {
+		case Commands:
+			if err := m.Reset(); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		case Command:
+			if m.UnknownFlags != nil {
+				restore(m.UnknownFlags)
+			}
+			continue
+		case Flag:
+			if m.Default != "" {
+				defaults = append(defaults, k)
+			}
+		}
+		if c.isAssignment(cmd) {
+			restore(target(cmd))
+		}
+	}
+	// a flag takes the Default of its longest name to have one, as it does its other options
+	sort.Slice(defaults, func(i, j int) bool {
+		return longerName(defaults[i], defaults[j])
+	})
+	done := map[interface{}]bool{}
+	for _, k := range defaults {
+		fl := c[k].(Flag)
+		if done[fl.Value] {
+			continue
+		}
+		done[fl.Value] = true
+		// set on a new value, so a flag.Value appending each value it is set with, is given only its default
+		pv := reflect.ValueOf(fl.Value)
+		nv := reflect.New(pv.Type().Elem())
+		if err := values.SetValue(nv.Interface(), fl.Default); err != nil {
+			errs = append(errs, fmt.Errorf("default of %s is invalid  %v", k, err))
+			continue
+		}
+		pv.Elem().Set(nv.Elem())
+	}
+	return errs.errorOrNil()
+}
+
+// assignments holds the variables set by a run, keyed by their pointer, until they are restored by the next.
+var assignments = map[interface{}]assignment{}
+var assignmentsLock sync.Mutex
+
+// assignment is the value a variable held before a run set it, and the value the run set it to.
+type assignment struct {
+	before reflect.Value
+	after  reflect.Value
+}
+
+// assign sets the variable of the given pointer to the given value, recording the value it held before, to be restored by the next run.
+func assign(p interface{}, v reflect.Value) {
+	pv := reflect.ValueOf(p).Elem()
+	assignmentsLock.Lock()
+	defer assignmentsLock.Unlock()
+	a, ok := assignments[p]
+	if !ok || !reflect.DeepEqual(pv.Interface(), a.after.Interface()) {
+		a.before = copyValue(pv)
+	}
+	a.after = copyValue(v)
+	assignments[p] = a
+	pv.Set(v)
+}
+
+// restore sets the variable of the given pointer back to the value it held before a run set it.
+// Variables changed since the run set them keep their value.
+func restore(p interface{}) {
+	assignmentsLock.Lock()
+	defer assignmentsLock.Unlock()
+	a, ok := assignments[p]
+	if !ok {
+		return
+	}
+	delete(assignments, p)
+	pv := reflect.ValueOf(p).Elem()
+	if reflect.DeepEqual(pv.Interface(), a.after.Interface()) {
+		pv.Set(a.before)
+	}
+}
+
+// copyValue copies the given value, including the items of a map or slice, so changes to either copy do not change the other.
+func copyValue(v reflect.Value) reflect.Value {
+	cv := reflect.New(v.Type()).Elem()
+	switch {
+	case v.Kind() == reflect.Map && !v.IsNil():
+		cv.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			cv.SetMapIndex(iter.Key(), iter.Value())
+		}
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		cv.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		reflect.Copy(cv, v)
+	default:
+		cv.Set(v)
+	}
+	return cv
+}
+
+// fileValues replaces any of the given parameters, beginning with the FileValuePrefix, with the contents of the file they name.
+func fileValues(params []string) ([]string, error) {
+	if FileValuePrefix == "" {
+		return params, nil
+	}
+	vals := make([]string, len(params))
+	for i, p := range params {
+		if !strings.HasPrefix(p, FileValuePrefix) {
+			vals[i] = p
+			continue
+		}
+		v, err := readFileValue(strings.TrimPrefix(p, FileValuePrefix))
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// readFileValue reads the value in the named file, or stdin when the name is "-"
+func readFileValue(name string) (string, error) {
+	if name == "-" {
+		return readStdin()
+	}
+	by, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value from %s  %v", name, err)
+	}
+	return strings.TrimRight(string(by), "\r\n"), nil
+}
@@ -16,11 +16,14 @@ package functions
 
 import (
 	"commandgo/values"
+	"fmt"
 	"reflect"
 	"runtime"
 	"strings"
 )
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // Checks if given interface is a func.
 // will be true for both global functions and methods.
 func IsFunc(i interface{}) bool {
@@ -28,22 +31,27 @@ func IsFunc(i interface{}) bool {
 }
 
 // Checks if the given interface is a method.
-// Must be a function (IsFunc returns true) AND have a first parameter of a struct reciever.
+// Must be a function (IsFunc returns true) AND have a first parameter of a named reciever type.
+// The reciever may be any named type, e.g. struct, slice or basic type, as a value or a pointer.
 // The reciever must have a method with the func name
 func IsMethod(i interface{}) bool {
 	if !IsFunc(i) {
 		return false
 	}
-	// func must have first param as struct
+	// func must have first param as named type
 	vt := reflect.TypeOf(i)
 	if vt.NumIn() < 1 {
 		return false
 	}
 	p1 := vt.In(0)
-	if p1.Kind() != reflect.Struct {
+	nt := p1
+	if nt.Kind() == reflect.Ptr {
+		nt = nt.Elem()
+	}
+	if nt.Name() == "" {
 		return false
 	}
-	// ensure that struct and func name match. (Not just a random struct as a parameter)
+	// ensure that reciever and func name match. (Not just a random type as a parameter)
 	if _, ok := p1.MethodByName(FuncName(i, false)); !ok {
 		return false
 	}
@@ -53,31 +61,79 @@ func IsMethod(i interface{}) bool {
 // CallFunc calls the given function interface using the given arguments.
 // interface must be a function (IsFunc returns true).
 // function is called as a global function, assuming all parameters are inputs.
-// If called with a method, will assume the receiver structure is a parameter.
+// If called with a method, a new, zero value receiver is constructed to call the method on.
+// Return values of the function are handled according to their position:
+// If the last return value is an error, it is returned as the error of the call and excluded from the values.
+// All other return values are returned, in the order they are declared, even when they are errors themselves.
+// e.g. func() (string, error) returns the string as a single value, func() (int, string) returns both values.
 func CallFunc(i interface{}, args ...string) ([]interface{}, error) {
 	sig := NewSignature(i)
 	inVals, err := ParseParameters(sig, args)
 	if err != nil {
 		return nil, err
 	}
+	return call(i, sig, inVals)
+}
+
+// CallFuncRaw calls the given function interface, passing the given arguments as they are, without parsing them.
+// The function must have a single parameter of either []string or variadic ...string.
+// Return values are handled in the same way as CallFunc.
+func CallFuncRaw(i interface{}, args []string) ([]interface{}, error) {
+	sig := NewSignature(i)
+	if len(sig.ParamTypes) != 1 || sig.ParamTypes[0] != reflect.TypeOf([]string{}) {
+		return nil, fmt.Errorf("%s can not be called with raw arguments, it must have a single []string parameter", FuncName(i, false))
+	}
+	var inVals []reflect.Value
+	if sig.IsVariadic {
+		for _, arg := range args {
+			inVals = append(inVals, reflect.ValueOf(arg))
+		}
+	} else {
+		inVals = []reflect.Value{reflect.ValueOf(args)}
+	}
+	return call(i, sig, inVals)
+}
+
+// call calls the given function with the given parameter values, separating any error from the return values.
+func call(i interface{}, sig *Signature, inVals []reflect.Value) ([]interface{}, error) {
+	if sig.Receiver != nil {
+		inVals = append([]reflect.Value{newReceiver(sig.Receiver)}, inVals...)
+	}
 	outVals := reflect.ValueOf(i).Call(inVals)
+	var err error
 
-	// check if an error returned
-	errInterface := reflect.TypeOf((*error)(nil)).Elem()
-	var vals []interface{}
-	err = nil
-	for _, ov := range outVals {
-		if ov.Kind() == reflect.Interface && ov.Type().Implements(errInterface) {
-			if !ov.IsNil() {
-				err = ov.Interface().(error)
-			}
-			continue
+	// check if the last value is an error
+	if len(outVals) > 0 && isError(outVals[len(outVals)-1]) {
+		ev := outVals[len(outVals)-1]
+		if !ev.IsNil() {
+			err = ev.Interface().(error)
 		}
-		vals = append(vals, ov.Interface())
+		outVals = outVals[:len(outVals)-1]
+	}
+	vals := make([]interface{}, len(outVals))
+	for i, ov := range outVals {
+		vals[i] = ov.Interface()
 	}
 	return vals, err
 }
 
+// newReceiver creates a new receiver of the given type.
+// Pointer receivers are given a pointer to a new zero value, maps are created empty.
+func newReceiver(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem())
+	}
+	if t.Kind() == reflect.Map {
+		return reflect.MakeMap(t)
+	}
+	return reflect.New(t).Elem()
+}
+
+// isError checks if the given value is declared as an error
+func isError(v reflect.Value) bool {
+	return v.Kind() == reflect.Interface && v.Type().Implements(errorType)
+}
+
 // Get the function name if the given interface is a func.
 // If not a func or is nil, , returns empty string
 // withPackage flag, when true privades a dot delmited <package>.<name>
@@ -94,9 +150,5 @@ func FuncName(i interface{}, withPackage bool) string {
 	if withPackage {
 		return fn
 	}
-	fns := strings.Split(fn, ".")
-	if len(fns) == 0 {
-		return fn
-	}
-	return fns[len(fns)-1]
+	return fn[strings.LastIndex(fn, ".")+1:]
 }
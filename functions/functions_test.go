@@ -0,0 +1,41 @@
+package functions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errTest = errors.New("test error")
+
+func TestCallFuncReturnValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      interface{}
+		args    []string
+		want    []interface{}
+		wantErr error
+	}{
+		{name: "no values", fn: func() {}, want: []interface{}{}},
+		{name: "value", fn: func(s string) string { return s }, args: []string{"one"}, want: []interface{}{"one"}},
+		{name: "nil error", fn: func() error { return nil }, want: []interface{}{}},
+		{name: "error", fn: func() error { return errTest }, want: []interface{}{}, wantErr: errTest},
+		{name: "value and nil error", fn: func(i int) (int, error) { return i, nil }, args: []string{"2"}, want: []interface{}{2}},
+		{name: "value and error", fn: func(i int) (int, error) { return i, errTest }, args: []string{"2"}, want: []interface{}{2}, wantErr: errTest},
+		{name: "values and nil error", fn: func() (int, string, error) { return 1, "two", nil }, want: []interface{}{1, "two"}},
+		{name: "values and error", fn: func() (int, string, error) { return 1, "two", errTest }, want: []interface{}{1, "two"}, wantErr: errTest},
+		{name: "error not last", fn: func() (error, string) { return errTest, "two" }, want: []interface{}{errTest, "two"}},
+		{name: "nil error not last", fn: func() (error, string) { return nil, "two" }, want: []interface{}{nil, "two"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CallFunc(tt.fn, tt.args...)
+			if err != tt.wantErr {
+				t.Fatalf("CallFunc() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CallFunc() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -72,10 +72,12 @@ func variadicParams(args []string, t reflect.Type) ([]reflect.Value, error) {
 }
 
 // Signature represents the signature of a method or func, both its parameters and its return types.
+// Receiver is the receiver type of a method, or nil for a func
 type Signature struct {
 	ParamTypes  []reflect.Type
 	ReturnTypes []reflect.Type
 	IsVariadic  bool
+	Receiver    reflect.Type
 }
 
 func (s Signature) String() string {
@@ -103,12 +105,13 @@ func NewSignature(i interface{}) *Signature {
 	if !IsFunc(i) {
 		panic("Not a function")
 	}
-	isMethod := IsMethod(i)
 	t := reflect.TypeOf(i)
 	var params []reflect.Type
+	var receiver reflect.Type
 	var index int
-	if isMethod {
+	if IsMethod(i) {
 		// Skip receiver param on methods
+		receiver = t.In(0)
 		index++
 	}
 	in := t.NumIn()
@@ -124,5 +127,6 @@ func NewSignature(i interface{}) *Signature {
 		ParamTypes:  params,
 		ReturnTypes: returns,
 		IsVariadic:  t.IsVariadic(),
+		Receiver:    receiver,
 	}
 }
@@ -1,3 +1,5 @@
 module commandgo
 
 go 1.16
+
+require golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
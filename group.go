@@ -0,0 +1,33 @@
+package commandgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlagGroup is a set of related flags, which may be added to any number of Commands.
+// Flags are mapped in the same way as in a Commands map, as pointers or Flags, and may use
+// Excludes and Requires to relate to one another, validating the group wherever it is added.
+// e.g. var Paging = commandgo.FlagGroup{"--limit": &Limit, "--offset": &Offset, "--sort": &Sort}
+type FlagGroup map[string]interface{}
+
+// AddGroups adds all the flags of the given groups to this commands.
+// Returns an error if a group maps anything other than flags, or a flag name is already mapped.
+func (c Commands) AddGroups(groups ...FlagGroup) error {
+	var errs Errors
+	for _, g := range groups {
+		for _, k := range Commands(g).sortedKeys() {
+			cmd := g[k]
+			if cmd == nil || !c.isAssignment(cmd) || !strings.HasPrefix(k, "-") {
+				errs = append(errs, fmt.Errorf("%s is not a flag and can not be in a flag group", k))
+				continue
+			}
+			if ek, ok := c.findKey(k); ok {
+				errs = append(errs, fmt.Errorf("flag %s is already mapped as %s", k, ek))
+				continue
+			}
+			c[k] = cmd
+		}
+	}
+	return errs.errorOrNil()
+}
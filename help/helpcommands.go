@@ -13,7 +13,8 @@ const (
 var HelpLibrary []*HelpSubject
 
 // HelpRequested is a flag to indicate the command is requesting help, rather than execution of the command.
-// ShowHelp flags can be mapped to this point which, when true, will redirect the execution to the help system.
+// ShowHelp flags can be mapped to this point which, when given, will redirect the execution to the help system.
+// The variable itself is only a marker and is not set, so each run has its own help state.
 var HelpRequested bool
 
 // ShowHelp is the main entry point for help.
@@ -21,7 +22,7 @@ var HelpRequested bool
 // returns the specific text for which ever is found matching the given name.
 func ShowHelp(cmd string, args ...string) []interface{} {
 	hs, hi := findSubject(cmd)
-	if hs == nil {
+	if hs == nil && len(args) > 0 {
 		hs, hi = findSubject(args[0])
 	}
 	if hs == nil {
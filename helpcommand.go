@@ -0,0 +1,244 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/functions"
+	"commandgo/help"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// HelpCommand is the command which shows the help of the commands following it, when not mapped itself.
+// e.g. "mytool help build" shows the help for the build command.
+const HelpCommand = "help"
+
+// showHelp gets the help for the command named in the given arguments.
+// When the help library has been generated, it is used, otherwise the help is taken from this map.
+func (c Commands) showHelp(key string, args []string) ([]interface{}, error) {
+	if len(help.HelpLibrary) > 0 {
+		return help.ShowHelp(key, args...), nil
+	}
+	var path []string
+	for _, arg := range args {
+		if arg == arguments.Terminator {
+			break
+		}
+		if !c.isFlag(arg) {
+			path = append(path, arg)
+		}
+	}
+	var sb strings.Builder
+	if err := c.Help(&sb, path...); err != nil {
+		return nil, err
+	}
+	return []interface{}{sb.String()}, nil
+}
+
+// Help writes the help of the command, named by the given path of command names, to the given writer.
+// The path names a command in this map, or in its sub maps. e.g. "build", "image" for the image command of the build sub map.
+// Names in the path, following the last one found, are ignored.  An empty path gives the help for this map.
+// The help lists how the command is used, its usage, aliases, sub commands, flags and examples.
+func (c Commands) Help(w io.Writer, path ...string) error {
+	m := c
+	names := []string{filepath.Base(os.Args[0])}
+	var key string
+	for _, p := range path {
+		k, ok := m.findKey(p)
+		if !ok || k == "" || k == WildcardKey || strings.HasPrefix(k, "-") {
+			break
+		}
+		names = append(names, k)
+		sc, ok := m[k].(Commands)
+		if !ok {
+			key = k
+			break
+		}
+		m = sc
+	}
+	if key == "" {
+		return m.writeMapHelp(w, strings.Join(names, " "))
+	}
+	return m.writeCommandHelp(w, strings.Join(names, " "), key)
+}
+
+// writeMapHelp writes the help of this map, as the given command line.
+func (c Commands) writeMapHelp(w io.Writer, cmdLine string) error {
+	var sb strings.Builder
+	if _, ok := c[""]; ok {
+		fmt.Fprintf(&sb, "Usage: %s\n", strings.Join(nonEmpty(cmdLine, "[flags]", paramSynopsis(c[""])), " "))
+	}
+	cmds := c.commandKeys()
+	if len(cmds) > 0 {
+		fmt.Fprintf(&sb, "Usage: %s [flags] <command>\n", cmdLine)
+	}
+	if cm, ok := c[""].(Command); ok && cm.Usage != "" {
+		fmt.Fprintf(&sb, "\n%s\n", cm.Usage)
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	if len(cmds) > 0 {
+		var names, usages []string
+		for _, k := range cmds {
+			names = append(names, strings.Join(c.commandNames(k), ", "))
+			usages = append(usages, commandUsage(c[k]))
+		}
+		if err := writeSection(w, "Commands", names, usages); err != nil {
+			return err
+		}
+	}
+	return c.writeFlagsSection(w)
+}
+
+// writeCommandHelp writes the help of the given command in this map, as the given command line.
+func (c Commands) writeCommandHelp(w io.Writer, cmdLine string, key string) error {
+	cmd := c[key]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Usage: %s\n", strings.Join(nonEmpty(cmdLine, "[flags]", paramSynopsis(cmd)), " "))
+	if u := commandUsage(cmd); u != "" {
+		fmt.Fprintf(&sb, "\n%s\n", u)
+	}
+	if als := c.commandAliases(key); len(als) > 0 {
+		fmt.Fprintf(&sb, "\nAliases: %s\n", strings.Join(als, ", "))
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	if err := c.writeFlagsSection(w); err != nil {
+		return err
+	}
+	if cm, ok := cmd.(Command); ok && len(cm.Examples) > 0 {
+		if _, err := fmt.Fprintf(w, "\nExamples:\n  %s\n", strings.Join(cm.Examples, "\n  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFlagsSection writes the usage of all the flags in this map, if it has any.
+func (c Commands) writeFlagsSection(w io.Writer) error {
+	if len(c.flagKeys(c.flagIndex())) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nFlags:"); err != nil {
+		return err
+	}
+	return c.Usage(w)
+}
+
+// writeSection writes a titled section of aligned names and texts.
+func writeSection(w io.Writer, title string, names, texts []string) error {
+	if _, err := fmt.Fprintf(w, "\n%s:\n", title); err != nil {
+		return err
+	}
+	return writeColumns(w, names, texts)
+}
+
+// commandKeys gets the main key of all the available commands in this map, excluding flags, the default and any aliases, in order.
+func (c Commands) commandKeys() []string {
+	var keys []string
+	done := map[string]bool{}
+	for _, k := range c.sortedKeys() {
+		if done[k] || k == "" || k == WildcardKey || strings.HasPrefix(k, "-") {
+			continue
+		}
+		if ok, _ := enabled(c[k]); !ok {
+			continue
+		}
+		names := c.commandNames(k)
+		for _, n := range names {
+			done[n] = true
+		}
+		keys = append(keys, names[0])
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commandNames gets the given command key and all its aliases, longest first.
+func (c Commands) commandNames(key string) []string {
+	names := append([]string{key}, c.commandAliases(key)...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+	return names
+}
+
+// commandAliases gets the other command keys in this map, mapped to the same func, method or sub map as the given key.
+func (c Commands) commandAliases(key string) []string {
+	var names []string
+	for _, k := range c.sortedKeys() {
+		if k == key || k == "" || strings.HasPrefix(k, "-") {
+			continue
+		}
+		if sameMapping(c[k], c[key]) {
+			names = append(names, k)
+		}
+	}
+	return names
+}
+
+// sameMapping checks if the two given mappings target the same func, method, sub map or variable.
+func sameMapping(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(target(a)), reflect.ValueOf(target(b))
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Func, reflect.Map, reflect.Ptr:
+		return va.Pointer() == vb.Pointer()
+	default:
+		return false
+	}
+}
+
+// commandUsage gets the Usage of the given mapping, if it is a Command, or a sub map with a default Command.
+func commandUsage(cmd interface{}) string {
+	if sc, ok := cmd.(Commands); ok {
+		cmd = sc[""]
+	}
+	cm, _ := cmd.(Command)
+	return cm.Usage
+}
+
+// paramSynopsis describes the parameters of the given mapping. e.g. "<string> [<int>...]"
+func paramSynopsis(cmd interface{}) string {
+	switch m := cmd.(type) {
+	case Commands:
+		return "<command>"
+	case Command:
+		if m.Raw {
+			return "[args...]"
+		}
+	}
+	f := target(cmd)
+	if !functions.IsFunc(f) {
+		return ""
+	}
+	sig := functions.NewSignature(f)
+	params := make([]string, len(sig.ParamTypes))
+	for i, pt := range sig.ParamTypes {
+		if sig.IsVariadic && i == len(sig.ParamTypes)-1 {
+			params[i] = fmt.Sprintf("[<%s>...]", typeName(pt.Elem()))
+			continue
+		}
+		params[i] = fmt.Sprintf("<%s>", typeName(pt))
+	}
+	return strings.Join(params, " ")
+}
+
+// nonEmpty gets the given strings, excluding any empty ones.
+func nonEmpty(s ...string) []string {
+	var ne []string
+	for _, str := range s {
+		if str != "" {
+			ne = append(ne, str)
+		}
+	}
+	return ne
+}
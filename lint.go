@@ -0,0 +1,72 @@
+package commandgo
+
+import (
+	"commandgo/help"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ReservedNames are the command and flag names, which Lint warns against mapping, as users expect them to behave the same in every tool.
+var ReservedNames = []string{HelpCommand, "version", help.HelpFlagShort, help.HelpFlagFull, "--version"}
+
+// Lint checks the names of all the commands and flags in this map, including its sub maps, against the common style for command lines.
+// Names should be lowercase, kebab-case, with no spaces, and should not be any of the ReservedNames.
+// Single letter flags, such as "-V", may be either case.
+// Returns a warning for every name not following the style, or none when they all do.
+// Lint does not prevent any mapping from running, it is intended for applications to enforce the style in their own tests.
+func (c Commands) Lint() []string {
+	return c.lint("")
+}
+
+func (c Commands) lint(path string) []string {
+	var warnings []string
+	for _, k := range c.sortedKeys() {
+		if k == "" || k == WildcardKey {
+			if sc, ok := c[k].(Commands); ok {
+				warnings = append(warnings, sc.lint(path)...)
+			}
+			continue
+		}
+		kp := strings.TrimSpace(strings.Join([]string{path, k}, " "))
+		for _, w := range lintName(k, c[k]) {
+			warnings = append(warnings, fmt.Sprintf("%q %s", kp, w))
+		}
+		if sc, ok := c[k].(Commands); ok {
+			warnings = append(warnings, sc.lint(kp)...)
+		}
+	}
+	return warnings
+}
+
+// lintName checks the given key, of the given mapping, follows the style for names.
+func lintName(key string, cmd interface{}) []string {
+	var warnings []string
+	name := strings.TrimLeft(key, "-")
+	if strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+		warnings = append(warnings, "should not contain spaces")
+	}
+	if len([]rune(name)) > 1 && strings.ToLower(name) != name {
+		warnings = append(warnings, fmt.Sprintf("should be lowercase, e.g. %q", styledName(key)))
+	} else if strings.Contains(name, "_") {
+		warnings = append(warnings, fmt.Sprintf("should be kebab-case, e.g. %q", styledName(key)))
+	}
+	if cmd != &help.HelpRequested {
+		for _, rn := range ReservedNames {
+			if key == rn {
+				warnings = append(warnings, "is a reserved name")
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// styledName gets the given key in the style for names. e.g. "--dryRun" as "--dry-run"
+func styledName(key string) string {
+	name := strings.TrimLeft(key, "-")
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || unicode.IsSpace(r)
+	}), "-")
+	return key[:len(key)-len(strings.TrimLeft(key, "-"))] + kebabCase(name)
+}
@@ -0,0 +1,54 @@
+package commandgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// RunLocked executes this commands in the same way as Run, holding the given lock file for the duration of the run.
+// Should another instance already hold the lock, it fails without executing any of the arguments.
+func (c Commands) RunLocked(lockFile string, args ...string) ([]interface{}, error) {
+	unlock, err := acquireLock(lockFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return c.Run(args...)
+}
+
+// acquireLock creates the lock file at the given path, containing the process id of this process.
+// If the file already exists, the lock is held by another instance and an error is returned.
+// returns a func to release the lock, removing the lock file.
+func acquireLock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, lockHeldError(path)
+		}
+		return nil, fmt.Errorf("failed to create lock file %s  %v", path, err)
+	}
+	_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to write lock file %s  %v", path, err)
+	}
+	return func() {
+		_ = os.Remove(path)
+	}, nil
+}
+
+// lockHeldError reports the lock file being held, naming the process holding it, when known.
+func lockHeldError(path string) error {
+	by, err := ioutil.ReadFile(path)
+	pid := strings.TrimSpace(string(by))
+	if err != nil || pid == "" {
+		return fmt.Errorf("another instance is already running.  lock file %s is held", path)
+	}
+	return fmt.Errorf("another instance is already running (process %s).  lock file %s is held. "+
+		"Remove the lock file if that process is no longer running", pid, path)
+}
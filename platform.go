@@ -0,0 +1,43 @@
+package commandgo
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// OnlyOn wraps the given func, method or Command so it is only available on the given platforms.
+// Platforms are given as GOOS, or GOOS/GOARCH.  e.g. "linux", "darwin/arm64".
+// On any other platform, the command fails, explaining the platforms it is available on.
+// e.g. "mount": commandgo.OnlyOn(fs.Mount, "linux", "darwin")
+func OnlyOn(cmd interface{}, platforms ...string) Command {
+	cm, ok := cmd.(Command)
+	if !ok {
+		cm = Command{Func: cmd}
+	}
+	enabledWhen := cm.EnabledWhen
+	cm.EnabledWhen = func() (bool, string) {
+		if !onPlatform(platforms) {
+			return false, fmt.Sprintf("only available on %s", strings.Join(platforms, ", "))
+		}
+		if enabledWhen != nil {
+			return enabledWhen()
+		}
+		return true, ""
+	}
+	return cm
+}
+
+// onPlatform checks if this process is running on any of the given platforms.
+func onPlatform(platforms []string) bool {
+	for _, p := range platforms {
+		parts := strings.SplitN(p, "/", 2)
+		if parts[0] != runtime.GOOS {
+			continue
+		}
+		if len(parts) == 1 || parts[1] == runtime.GOARCH {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/help"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// secretFlags prompts for the value of any Secret flags not in the given flags, adding the values given to the flags.
+// Flags are only prompted for when stdin is a terminal, and help has not been requested.
+func (c Commands) secretFlags(flags flagMap, ix flagIndex) error {
+	if flags.containsAny([]string{help.HelpFlagShort, help.HelpFlagFull}) {
+		return nil
+	}
+	var secrets []*indexedFlag
+	for _, f := range ix {
+		if f.options.Secret && !flags.containsAny(f.names) {
+			secrets = append(secrets, f)
+		}
+	}
+	if len(secrets) == 0 || !isTerminal(os.Stdin) {
+		return nil
+	}
+	// prompted for in the order of their names
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].names[0] < secrets[j].names[0]
+	})
+	for _, f := range secrets {
+		prompt := f.options.Usage
+		if prompt == "" {
+			prompt = strings.TrimLeft(f.names[0], "-")
+		}
+		v, err := readSecret(prompt)
+		if err != nil {
+			return fmt.Errorf("%s could not be read  %v", f.names[0], err)
+		}
+		flags[f.names[0]] = &arguments.Argument{Name: f.names[0], Position: -1, Parameters: []string{v}}
+	}
+	return nil
+}
+
+// readSecret prompts, on stderr, for a line from the terminal on stdin, with echo turned off while it is typed.
+func readSecret(prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// isTerminal checks if the given file is a terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
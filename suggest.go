@@ -0,0 +1,58 @@
+package commandgo
+
+import "strings"
+
+// suggestKey finds the key in this map closest to the given unknown name, for suggesting as an alternative.
+// Only keys of the same kind, flags or commands, within an edit distance of a third of the name's length, rounded up, are suggested.
+// returns empty if no key is close enough.
+func (c Commands) suggestKey(name string) string {
+	isFlag := strings.HasPrefix(name, "-")
+	limit := (len(name) + 2) / 3
+	var best string
+	bestD := limit + 1
+	for _, k := range c.sortedKeys() {
+		if k == "" || k == WildcardKey || strings.HasPrefix(k, "-") != isFlag {
+			continue
+		}
+		if ok, _ := enabled(c[k]); !ok {
+			continue
+		}
+		d := levenshtein(strings.ToLower(name), strings.ToLower(k))
+		if d < bestD {
+			best, bestD = k, d
+		}
+	}
+	return best
+}
+
+// levenshtein calculates the edit distance between the two given strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,172 @@
+package commandgo
+
+import (
+	"commandgo/help"
+	"commandgo/values"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UsageWidth is the width, in characters, usage listings are wrapped to.
+var UsageWidth = 80
+
+// Usage writes an aligned listing of all the flags in this map to the given writer.
+// Each flag is listed with all its names, a placeholder for its value, its usage and its current value as the default.
+// Hidden flags are not listed.  The options of a flag may be given on the Flag of any of its names.
+func (c Commands) Usage(w io.Writer) error {
+	var names, usages []string
+	ix := c.flagIndex()
+	for _, key := range c.flagKeys(ix) {
+		f := ix.flag(c, key)
+		names = append(names, flagSynopsis(f))
+		usages = append(usages, flagUsage(f))
+	}
+	return writeColumns(w, names, usages)
+}
+
+// flagKeys gets the longest name of each flag in the given index of this map, excluding hidden flags, in order.
+func (c Commands) flagKeys(ix flagIndex) []string {
+	var keys []string
+	for t, f := range ix {
+		if t == &help.HelpRequested || f.options.Hidden || !hasFlagName(f.names) {
+			continue
+		}
+		keys = append(keys, f.names[0])
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ni, nj := strings.TrimLeft(keys[i], "-"), strings.TrimLeft(keys[j], "-"); ni != nj {
+			return ni < nj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// hasFlagName checks if any of the given names is a flag, beginning with a dash.
+func hasFlagName(names []string) bool {
+	for _, n := range names {
+		if strings.HasPrefix(n, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// flagSynopsis gives all the names of the given flag, shortest first, followed by its value placeholder.
+func flagSynopsis(f *indexedFlag) string {
+	names := make([]string, len(f.names))
+	for i, n := range f.names {
+		names[len(names)-1-i] = n
+	}
+	s := strings.Join(names, ", ")
+	if p := placeholder(f); p != "" {
+		s = fmt.Sprintf("%s <%s>", s, p)
+	}
+	return s
+}
+
+// placeholder gets the name of the value of the given flag. bool flags and counters have none.
+func placeholder(f *indexedFlag) string {
+	if f.options.Placeholder != "" {
+		return f.options.Placeholder
+	}
+	if f.options.Counter || values.IsKind(f.value, reflect.Bool) {
+		return ""
+	}
+	return typeName(reflect.TypeOf(f.value).Elem())
+}
+
+// typeName gives the name of the given type, or the type it points to, in lower case.  Unnamed types give their full type.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+	return strings.ToLower(t.Name())
+}
+
+// flagUsage gives the usage text of the given flag, followed by any condition on its use, and its default.
+// The default is the Default of a Flag, otherwise the current value, when not a zero value.
+// The options are those of all the flag's names, so a Secret on any of them hides its value.
+func flagUsage(f *indexedFlag) string {
+	var usage []string
+	fl := f.options
+	if fl.Usage != "" {
+		usage = append(usage, fl.Usage)
+	}
+	if len(fl.Choices) > 0 {
+		usage = append(usage, fmt.Sprintf("(one of %s)", strings.Join(fl.Choices, ", ")))
+	}
+	if fl.When != "" {
+		usage = append(usage, fmt.Sprintf("(only with %s)", fl.When))
+	}
+	if fl.Secret {
+		return strings.Join(usage, " ")
+	}
+	if fl.Default != "" {
+		return strings.Join(append(usage, fmt.Sprintf("(default %s)", fl.Default)), " ")
+	}
+	v := reflect.ValueOf(f.value).Elem()
+	if !v.IsZero() {
+		d := values.GetValue(f.value)
+		if s, ok := d.(string); ok {
+			d = fmt.Sprintf("%q", s)
+		}
+		usage = append(usage, fmt.Sprintf("(default %v)", d))
+	}
+	return strings.Join(usage, " ")
+}
+
+// writeColumns writes the given names and texts as two aligned columns, wrapping the texts to the UsageWidth.
+func writeColumns(w io.Writer, names, texts []string) error {
+	var width int
+	for _, n := range names {
+		if len(n) > width {
+			width = len(n)
+		}
+	}
+	indent := strings.Repeat(" ", width+4)
+	for i, n := range names {
+		lines := wrap(texts[i], UsageWidth-len(indent))
+		first := ""
+		if len(lines) > 0 {
+			first = lines[0]
+		}
+		line := strings.TrimRight(fmt.Sprintf("  %-*s  %s", width, n, first), " ")
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, l := range lines[1:] {
+			if _, err := fmt.Fprintf(w, "%s%s\n", indent, l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// wrap splits the given text into lines no longer than the given width, breaking on spaces.
+// Words longer than the width are not broken.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		var line string
+		for _, word := range strings.Fields(para) {
+			if line != "" && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package commandgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsageOptionsOfAliases(t *testing.T) {
+	password := "hunter2"
+	retries := 3
+	tests := []struct {
+		name    string
+		cmds    Commands
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "secret on key",
+			cmds:    Commands{"--password": Flag{Value: &password, Secret: true}, "-p": &password},
+			want:    []string{"-p, --password <string>"},
+			notWant: []string{"hunter2"},
+		},
+		{
+			name:    "secret on alias",
+			cmds:    Commands{"-p": Flag{Value: &password, Secret: true}, "--password": &password},
+			want:    []string{"-p, --password <string>"},
+			notWant: []string{"hunter2"},
+		},
+		{
+			name:    "hidden on alias",
+			cmds:    Commands{"-p": Flag{Value: &password, Hidden: true}, "--password": &password, "-r": &retries},
+			want:    []string{"-r <int>"},
+			notWant: []string{"password"},
+		},
+		{
+			name: "placeholder on alias",
+			cmds: Commands{"-r": Flag{Value: &retries, Placeholder: "count"}, "--retries": &retries},
+			want: []string{"-r, --retries <count>"},
+		},
+		{
+			name: "usage on alias",
+			cmds: Commands{"-r": Flag{Value: &retries, Usage: "times to retry"}, "--retries": &retries},
+			want: []string{"-r, --retries <int>  times to retry (default 3)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.cmds.Usage(&sb); err != nil {
+				t.Fatalf("Usage() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(sb.String(), w) {
+					t.Errorf("Usage() = %q, want it to contain %q", sb.String(), w)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(sb.String(), nw) {
+					t.Errorf("Usage() = %q, should not contain %q", sb.String(), nw)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Rob Gilham
+//
+// Licensed under the Apache License, Version newtype.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Parser parses the given string into a value of the type it is registered for.
+type Parser func(s string) (interface{}, error)
+
+var parsers = map[reflect.Type]Parser{}
+var parsersLock sync.RWMutex
+
+// Register sets the given parser to parse all values of the given type, in place of any built in parsing of that type.
+// Registered types are parsed wherever they appear, including as the items of slices, arrays and maps and when pointed to.
+// e.g. values.Register(reflect.TypeOf(UserID(0)), ParseUserID)
+// The parser must return a value of the given type.  A nil parser removes any parser registered for the type.
+func Register(t reflect.Type, p Parser) {
+	parsersLock.Lock()
+	defer parsersLock.Unlock()
+	if p == nil {
+		delete(parsers, t)
+		return
+	}
+	parsers[t] = p
+}
+
+// registeredParser gets the parser registered for the given type, if any.
+func registeredParser(t reflect.Type) (Parser, bool) {
+	parsersLock.RLock()
+	defer parsersLock.RUnlock()
+	p, ok := parsers[t]
+	return p, ok
+}
+
+// parseRegistered parses the given string with the given parser, checking it gives a value of the given type.
+func parseRegistered(s string, t reflect.Type, p Parser) (interface{}, error) {
+	v, err := p(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be read as a %s  %v", s, t.String(), err)
+	}
+	if v == nil {
+		return reflect.Zero(t).Interface(), nil
+	}
+	if reflect.TypeOf(v) != t {
+		return nil, fmt.Errorf("parser registered for %s returned a %T", t.String(), v)
+	}
+	return v, nil
+}
+
+// hasRegistered checks if the given type, or any type it contains, has a registered parser.
+func hasRegistered(t reflect.Type) bool {
+	if _, ok := registeredParser(t); ok {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return hasRegistered(t.Key()) || hasRegistered(t.Elem())
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasRegistered(t.Elem())
+	default:
+		return false
+	}
+}
+
+// registeredMapFromString parses the given json object into a map of the given type, parsing its keys and values as their own types,
+// so any with a registered parser are parsed by it.  String values are parsed as their content, any others as their json.
+func registeredMapFromString(s string, t reflect.Type) (interface{}, error) {
+	mv := reflect.MakeMap(t)
+	if s == "" {
+		return mv.Interface(), nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, err
+	}
+	for k, rv := range raw {
+		kv, err := ValueFromString(k, t.Key())
+		if err != nil {
+			return nil, err
+		}
+		vs := string(rv)
+		var str string
+		if err := json.Unmarshal(rv, &str); err == nil {
+			vs = str
+		}
+		v, err := ValueFromString(vs, t.Elem())
+		if err != nil {
+			return nil, fmt.Errorf("%s %v", k, err)
+		}
+		mv.SetMapIndex(reflect.ValueOf(kv), reflect.ValueOf(v))
+	}
+	return mv.Interface(), nil
+}
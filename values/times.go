@@ -0,0 +1,148 @@
+// Copyright 2020 Rob Gilham
+//
+// Licensed under the Apache License, Version newtype.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package values
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock provides the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock giving the current system time.
+type SystemClock struct{}
+
+// FixedClock is a Clock always giving the same time. Used to give deterministic results, e.g. when testing.
+type FixedClock time.Time
+
+// CurrentClock is the Clock used to establish the current time, when parsing time keywords and relative times.
+// May be replaced with a FixedClock, or any other Clock, to control the time arguments are parsed against.
+var CurrentClock Clock = SystemClock{}
+
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// EpochUnit is the unit of integer unix epoch times. e.g. time.Second or time.Millisecond
+// When zero, the default, the unit is detected by the magnitude of the value.
+var EpochUnit time.Duration
+
+// timeFromString parses the given string into a time.Time.
+// As well as times in the TimeFormat, the following are accepted:
+// keywords "now", "today", "yesterday" and "tomorrow". The days being midnight, local time, of that day.
+// relative times, a signed duration from the current time. e.g. "-2h", "+90m", "-1h30m".
+// Relative times may also be given in whole days or weeks. e.g. "+3d", "-2w"
+// unix epoch times, as an unsigned integer in the EpochUnit.
+func timeFromString(s string, t reflect.Type) (interface{}, error) {
+	now := CurrentClock.Now()
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight(now, 0), nil
+	case "yesterday":
+		return midnight(now, -1), nil
+	case "tomorrow":
+		return midnight(now, 1), nil
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		return relativeTime(s, now, t)
+	}
+	tm, err := time.Parse(TimeFormat, s)
+	if err != nil {
+		if n, perr := strconv.ParseUint(s, 10, 63); perr == nil {
+			et, err := epochTime(s, int64(n), t)
+			if err != nil {
+				return nil, err
+			}
+			return et, nil
+		}
+		return nil, fmt.Errorf("%s could not be read as a %s  %v", s, t.String(), err)
+	}
+	return tm, nil
+}
+
+// epochTime converts the given epoch value, in the EpochUnit, into a time.
+// When no EpochUnit is set, values up to 11 digits are seconds, 14 digits millis, 17 digits micros and any larger, nanoseconds.
+// Fails when the value is too large to be held as a time in its unit.
+func epochTime(s string, n int64, t reflect.Type) (time.Time, error) {
+	unit := EpochUnit
+	if unit == 0 {
+		switch {
+		case n < 1e11:
+			unit = time.Second
+		case n < 1e14:
+			unit = time.Millisecond
+		case n < 1e17:
+			unit = time.Microsecond
+		default:
+			unit = time.Nanosecond
+		}
+	}
+	m := int64(unit)
+	if unit >= time.Second {
+		m = int64(unit / time.Second)
+	}
+	if n > math.MaxInt64/m {
+		return time.Time{}, fmt.Errorf("%s is out of range for a %s in %v units", s, t.String(), unit)
+	}
+	if unit >= time.Second {
+		return time.Unix(n*m, 0), nil
+	}
+	return time.Unix(0, n*m), nil
+}
+
+// relativeTime parses the given signed duration and applies it to the given time.
+func relativeTime(s string, now time.Time, t reflect.Type) (time.Time, error) {
+	if len(s) > 2 {
+		days := 0
+		switch s[len(s)-1] {
+		case 'd':
+			days = 1
+		case 'w':
+			days = 7
+		}
+		if days > 0 {
+			n, err := strconv.Atoi(s[:len(s)-1])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%s could not be read as a relative %s  %v", s, t.String(), err)
+			}
+			return now.AddDate(0, 0, n*days), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s could not be read as a relative %s  %v", s, t.String(), err)
+	}
+	return now.Add(d), nil
+}
+
+// midnight gets the start of the day, the given number of days from the given time.
+func midnight(tm time.Time, days int) time.Time {
+	y, m, d := tm.Date()
+	return time.Date(y, m, d+days, 0, 0, 0, 0, tm.Location())
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Rob Gilham
+//
+// Licensed under the Apache License, Version newtype.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package values
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueToString gives the given value as a string, in the form ValueFromString parses back into the same value.
+// Pointers give the value they point to, nil pointers and nil values give an empty string.
+// Slices and arrays are given as their items, delimited with the SliceDelimiter.  Times are given in the TimeFormat.
+// Structs with an encoding.TextMarshaler are given in that form, other structs, and maps, are given as json.
+// Values implementing the standard flag.Value interface, without a TextMarshaler, are given by their String method.
+func ValueToString(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", nil
+	}
+	switch vv := rv.Interface().(type) {
+	case time.Time:
+		return vv.Format(TimeFormat), nil
+	case time.Duration:
+		return vv.String(), nil
+	case url.URL:
+		return vv.String(), nil
+	case json.RawMessage:
+		return string(vv), nil
+	}
+	if tm, ok := textMarshaler(rv); ok {
+		by, err := tm.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(by), nil
+	}
+	if fv, ok := flagValue(rv); ok {
+		return fv.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		return ValueToString(rv.Interface())
+
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for i := range items {
+			s, err := ValueToString(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, SliceDelimiter), nil
+
+	case reflect.Map, reflect.Struct:
+		by, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(by), nil
+
+	case reflect.Float64, reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), nil
+
+	default:
+		return fmt.Sprint(rv.Interface()), nil
+	}
+}
+
+// textMarshaler gets the encoding.TextMarshaler of the given value, or a pointer to it, if it has one.
+func textMarshaler(rv reflect.Value) (encoding.TextMarshaler, bool) {
+	if tm, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		return tm, true
+	}
+	if rv.CanAddr() {
+		tm, ok := rv.Addr().Interface().(encoding.TextMarshaler)
+		return tm, ok
+	}
+	return nil, false
+}
+
+// flagValue gets the flag.Value of the given value, or a pointer to it, if it has one.
+func flagValue(rv reflect.Value) (flag.Value, bool) {
+	if fv, ok := rv.Interface().(flag.Value); ok {
+		return fv, true
+	}
+	if rv.CanAddr() {
+		fv, ok := rv.Addr().Interface().(flag.Value)
+		return fv, ok
+	}
+	return nil, false
+}
@@ -18,6 +18,7 @@ package values
 import (
 	"encoding"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -29,20 +30,44 @@ import (
 var SliceDelimiter = ","
 var TimeFormat = time.RFC3339
 
+var (
+	urlType             = reflect.TypeOf(url.URL{})
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
 // ValueFromString attempts to parse the given string, into the given type.
-// If the string is parsable and the type is supported, the resulting value is returned as an interface.
+// If the string is parsable and the type is supported, the resulting value, of the given type, is returned as an interface.
 // Most types are supported with the exception of channels, functions.
 // struct's must support either the json.Unmarshaler or encoding.TextUnmarshaler interfaces.
 // Special cases for structs: URL and Time both supported
+// Time also accepts the keywords "now", "today", "yesterday" and relative times such as "-2h" or "+3d".
 // The argument string is passed to these to unmarshal into the struct.
 // slices/arrays are parsed as comma delimited items. Change the SliceDelimiter for something else.
 // All supported types can be used as item types of the array.
-// Base types float, int, bool string are supported.
+// Fixed length arrays are parsed in the same way as slices, but must have exactly the number of items the array holds.
+// Base types float, int, uint, complex, bool string are supported.
+// Integer values must be within the range of their type.
+// complex values are parsed in the form "1+2i"
 // Maps are parsed as json structures. e.g. -mapflag '{"mykey": "myvalue", "isIt": true}'
+// Empty interfaces (interface{}) receive json objects and arrays parsed as json, any other argument as its raw string.
+// json.RawMessage receives the argument as is, when it is valid json, otherwise the argument as a json string.
+// Types with a parser set by Register are parsed by that parser, in place of any of the above.
+// Otherwise, types implementing the standard flag.Value interface are parsed by their Set method.
 func ValueFromString(v string, t reflect.Type) (interface{}, error) {
+	if p, ok := registeredParser(t); ok {
+		return parseRegistered(v, t, p)
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(flagValueType) {
+		return flagValueFromString(v, t)
+	}
 	switch t.Kind() {
 	case reflect.Interface:
-		return ValueFromString(v, t.Elem())
+		return interfaceFromString(v, t)
 
 	case reflect.Ptr:
 		v, err := ValueFromString(v, t.Elem())
@@ -57,17 +82,32 @@ func ValueFromString(v string, t reflect.Type) (interface{}, error) {
 		return structureFromString(v, t)
 
 	case reflect.Slice:
+		if t == rawMessageType {
+			return rawMessageFromString(v)
+		}
 		return sliceFromString(v, t)
 
+	case reflect.Array:
+		return arrayFromString(v, t)
+
 	case reflect.Map:
+		if hasRegistered(t) {
+			return registeredMapFromString(v, t)
+		}
 		return mapFromString(v, t)
 
 	case reflect.Float64, reflect.Float32:
 		return floatFromString(v, t)
 
+	case reflect.Complex128, reflect.Complex64:
+		return complexFromString(v, t)
+
 	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
 		return intFromString(v, t)
 
+	case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint, reflect.Uintptr:
+		return uintFromString(v, t)
+
 	case reflect.Bool:
 		return boolFromString(v, t)
 
@@ -79,14 +119,55 @@ func ValueFromString(v string, t reflect.Type) (interface{}, error) {
 	}
 }
 
+// IsSupported checks if the given type can be parsed from a string by ValueFromString.
+func IsSupported(t reflect.Type) bool {
+	if _, ok := registeredParser(t); ok {
+		return true
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(flagValueType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return t.NumMethod() == 0
+
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return t == rawMessageType || IsSupported(t.Elem())
+
+	case reflect.Struct:
+		pt := reflect.PtrTo(t)
+		return t == urlType || t == timeType || pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+
+	case reflect.Map, reflect.Float64, reflect.Float32, reflect.Complex128, reflect.Complex64,
+		reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int, reflect.Bool, reflect.String,
+		reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint, reflect.Uintptr:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// IsKind checks if the given value is of the given kind, or a pointer to a value of that kind.
+// nil pointers are checked by the type they point to.
 func IsKind(i interface{}, k reflect.Kind) bool {
 	t := reflect.ValueOf(i)
 	if t.Kind() == reflect.Ptr {
+		if t.IsNil() {
+			return isTypeKind(t.Type().Elem(), k)
+		}
 		return IsKind(t.Elem().Interface(), k)
 	}
 	return t.Kind() == k
 }
 
+func isTypeKind(t reflect.Type, k reflect.Kind) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == k
+}
+
 func GetValue(r interface{}) interface{} {
 	t := reflect.TypeOf(r)
 	if t.Kind() == reflect.Ptr {
@@ -97,7 +178,16 @@ func GetValue(r interface{}) interface{} {
 
 // Sets the given receiver with the given value.
 // Assigns the value or a pointer to it, depending on the reciever type
+// Receivers of a pointer to a pointer, e.g. **int or **[]string, are left nil until they are set,
+// allowing an unset value to be distinguished from its zero value.
+// Receivers implementing the standard flag.Value interface are set by calling their Set method.
 func SetValue(r interface{}, val string) error {
+	if fv, ok := r.(flag.Value); ok && val != "" && !hasRegistered(reflect.TypeOf(r).Elem()) {
+		if err := fv.Set(val); err != nil {
+			return fmt.Errorf("%s could not be read as a %s  %v", val, reflect.TypeOf(r).Elem().String(), err)
+		}
+		return nil
+	}
 	iVal, err := ValueFromString(val, reflect.TypeOf(r))
 	if err != nil {
 		return err
@@ -116,13 +206,23 @@ func SetValue(r interface{}, val string) error {
 	return nil
 }
 
+// flagValueFromString parses the given string into a new value of the given type, with the Set method of its flag.Value
+func flagValueFromString(s string, t reflect.Type) (interface{}, error) {
+	pv := reflect.New(t)
+	if s != "" {
+		if err := pv.Interface().(flag.Value).Set(s); err != nil {
+			return nil, fmt.Errorf("%s could not be read as a %s  %v", s, t.String(), err)
+		}
+	}
+	return pv.Elem().Interface(), nil
+}
+
 func structureFromString(s string, t reflect.Type) (interface{}, error) {
-	pStr := reflect.New(t)
 	if s == "" {
-		return pStr.Elem().Interface(), nil
+		return reflect.Zero(t).Interface(), nil
 	}
 
-	if t == reflect.TypeOf(url.URL{}) {
+	if t == urlType {
 		u, err := url.Parse(s)
 		if err != nil {
 			return nil, fmt.Errorf("%s could not be read as a %s  %v", s, t.String(), err)
@@ -130,25 +230,22 @@ func structureFromString(s string, t reflect.Type) (interface{}, error) {
 		return *u, nil
 	}
 
-	if t == reflect.TypeOf(time.Time{}) {
-		u, err := time.Parse(TimeFormat, s)
-		if err != nil {
-			return nil, fmt.Errorf("%s could not be read as a %s  %v", s, t.String(), err)
-		}
-		return u, nil
+	if t == timeType {
+		return timeFromString(s, t)
 	}
 
+	pStr := reflect.New(t)
 	// If supports json, treat argument as json string
-	if t.Implements(reflect.TypeOf((json.Unmarshaler)(nil))) {
+	if pStr.Type().Implements(jsonUnmarshalerType) {
 		err := json.Unmarshal([]byte(s), pStr.Interface())
 		if err != nil {
 			return nil, err
 		}
-		return pStr.Interface(), nil
+		return pStr.Elem().Interface(), nil
 	}
 
 	// If supports textUnmarshal, unmarshal argument into new object
-	if t.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil))) {
+	if pStr.Type().Implements(textUnmarshalerType) {
 		tu, ok := pStr.Interface().(encoding.TextUnmarshaler)
 		if !ok {
 			panic("Supposed supported interface didn't cast into that interface")
@@ -157,13 +254,45 @@ func structureFromString(s string, t reflect.Type) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		return pStr.Interface(), nil
+		return pStr.Elem().Interface(), nil
 	}
 
 	return nil, fmt.Errorf("failed to unmarshal argument %s into paramter %s as that parameter does not support a supported unmarshalling interface."+
 		"Must support, json.Unmarshaler or encoding.TextUnmarshaler", s, t)
 }
 
+// interfaceFromString passes the string through, untyped, to empty interfaces.
+// json objects and arrays are parsed into their generic json form, all other strings are used as is.
+func interfaceFromString(s string, t reflect.Type) (interface{}, error) {
+	if t.NumMethod() > 0 {
+		return nil, fmt.Errorf("%s types are not supported as command line arguments", t.String())
+	}
+	ts := strings.TrimSpace(s)
+	if strings.HasPrefix(ts, "{") || strings.HasPrefix(ts, "[") {
+		var i interface{}
+		if err := json.Unmarshal([]byte(ts), &i); err != nil {
+			return nil, fmt.Errorf("%s could not be read as json  %v", s, err)
+		}
+		return i, nil
+	}
+	return s, nil
+}
+
+// rawMessageFromString passes valid json through as is. Any other string is encoded as a json string.
+func rawMessageFromString(s string) (interface{}, error) {
+	if s == "" {
+		return json.RawMessage(nil), nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s), nil
+	}
+	by, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(by), nil
+}
+
 func sliceFromString(s string, t reflect.Type) (interface{}, error) {
 	ss := strings.Split(s, SliceDelimiter)
 	sv := reflect.MakeSlice(t, 0, len(ss))
@@ -172,15 +301,30 @@ func sliceFromString(s string, t reflect.Type) (interface{}, error) {
 		if err != nil {
 			return nil, fmt.Errorf("%s could not be read as a %s", sa, t.Elem().String())
 		}
-		ev := reflect.ValueOf(sel)
-		if ev.Kind() == reflect.Ptr {
-			ev = ev.Elem()
-		}
-		sv = reflect.Append(sv, ev)
+		sv = reflect.Append(sv, reflect.ValueOf(sel))
 	}
 	return sv.Interface(), nil
 }
 
+func arrayFromString(s string, t reflect.Type) (interface{}, error) {
+	av := reflect.New(t).Elem()
+	if s == "" {
+		return av.Interface(), nil
+	}
+	ss := strings.Split(s, SliceDelimiter)
+	if len(ss) != t.Len() {
+		return nil, fmt.Errorf("%s could not be read as a %s, expected %d items, found %d", s, t.String(), t.Len(), len(ss))
+	}
+	for i, sa := range ss {
+		sel, err := ValueFromString(sa, t.Elem())
+		if err != nil {
+			return nil, fmt.Errorf("%s could not be read as a %s", sa, t.Elem().String())
+		}
+		av.Index(i).Set(reflect.ValueOf(sel))
+	}
+	return av.Interface(), nil
+}
+
 // Map is parsed as json
 func mapFromString(s string, t reflect.Type) (interface{}, error) {
 	mp := reflect.New(t)
@@ -192,26 +336,43 @@ func mapFromString(s string, t reflect.Type) (interface{}, error) {
 	} else {
 		mp.Elem().Set(reflect.MakeMap(t))
 	}
-	return mp.Interface(), nil
+	return mp.Elem().Interface(), nil
 }
 
 func floatFromString(s string, t reflect.Type) (interface{}, error) {
 	var f float64
 	if s != "" {
-		fl, err := strconv.ParseFloat(s, 64)
+		fl, err := strconv.ParseFloat(s, t.Bits())
 		if err != nil {
+			if isRangeError(err) {
+				return nil, fmt.Errorf("%s is out of range for a %s", s, t.String())
+			}
 			return nil, fmt.Errorf("%s could not be read as a %s", s, t.String())
 		}
 		f = fl
 	}
-	iv := reflect.New(t)
-	iv.Elem().SetFloat(f)
+	iv := reflect.New(t).Elem()
+	iv.SetFloat(f)
 	return iv.Interface(), nil
 }
 
+func complexFromString(s string, t reflect.Type) (interface{}, error) {
+	var c complex128
+	if s != "" {
+		cx, err := strconv.ParseComplex(s, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%s could not be read as a %s", s, t.String())
+		}
+		c = cx
+	}
+	cv := reflect.New(t).Elem()
+	cv.SetComplex(c)
+	return cv.Interface(), nil
+}
+
 func intFromString(s string, t reflect.Type) (interface{}, error) {
 	// Special cases
-	if t == reflect.TypeOf(time.Duration(0)) {
+	if t == durationType {
 		var d time.Duration
 		if s != "" {
 			du, err := time.ParseDuration(s)
@@ -220,18 +381,46 @@ func intFromString(s string, t reflect.Type) (interface{}, error) {
 			}
 			d = du
 		}
-		return &d, nil
+		return d, nil
+	}
+
+	var i int64
+	if s != "" {
+		ii, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			if isRangeError(err) {
+				return nil, fmt.Errorf("%s is out of range for a %s", s, t.String())
+			}
+			return nil, fmt.Errorf("%s could not be read as a %s", s, t.String())
+		}
+		i = ii
 	}
+	iv := reflect.New(t).Elem()
+	iv.SetInt(i)
+	return iv.Interface(), nil
+}
 
-	var i int
+func uintFromString(s string, t reflect.Type) (interface{}, error) {
+	var u uint64
 	if s != "" {
-		ii, err := strconv.ParseInt(s, 10, 64)
+		uu, err := strconv.ParseUint(s, 10, t.Bits())
 		if err != nil {
+			if isRangeError(err) {
+				return nil, fmt.Errorf("%s is out of range for a %s", s, t.String())
+			}
 			return nil, fmt.Errorf("%s could not be read as a %s", s, t.String())
 		}
-		i = int(ii)
+		u = uu
 	}
-	return i, nil
+	uv := reflect.New(t).Elem()
+	uv.SetUint(u)
+	return uv.Interface(), nil
+}
+
+// isRangeError checks if the given error is from parsing a number outside the range of its type.
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
 }
 
 func boolFromString(s string, t reflect.Type) (interface{}, error) {
@@ -243,7 +432,9 @@ func boolFromString(s string, t reflect.Type) (interface{}, error) {
 		}
 		b = bb
 	}
-	return b, nil
+	bv := reflect.New(t).Elem()
+	bv.SetBool(b)
+	return bv.Interface(), nil
 }
 
 func stringFromString(s string, t reflect.Type) (interface{}, error) {
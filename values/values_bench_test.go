@@ -0,0 +1,33 @@
+package values
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func BenchmarkValueFromString(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		s    string
+		t    reflect.Type
+	}{
+		{name: "string", s: "hello", t: reflect.TypeOf("")},
+		{name: "int", s: "42", t: reflect.TypeOf(0)},
+		{name: "bool", s: "true", t: reflect.TypeOf(false)},
+		{name: "duration", s: "90s", t: reflect.TypeOf(time.Duration(0))},
+		{name: "slice", s: "1,2,3,4", t: reflect.TypeOf([]int{})},
+		{name: "pointer", s: "42", t: reflect.TypeOf((*int)(nil))},
+		{name: "time", s: "2020-01-02T15:04:05Z", t: reflect.TypeOf(time.Time{})},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ValueFromString(bm.s, bm.t); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
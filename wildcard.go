@@ -0,0 +1,78 @@
+package commandgo
+
+import (
+	"commandgo/arguments"
+	"commandgo/values"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WildcardKey maps a pointer to a map, with string keys, which collects all the flags on the command line, not mapped in the same map.
+// Each unknown flag is added to the map, keyed by its name without leading dashes, with the argument following it as its value.
+// Flags without a following argument are given the value "true".  A flag given more than once takes its last value.
+// e.g. "*": &Options, with Options a map[string]string, collects "-level 3 --dry-run" as {"level": "3", "dry-run": "true"}
+// Unknown flags following a sub command of the same map are not collected, being left for the sub command, which may map them itself.
+const WildcardKey = "*"
+
+// isWildcard checks if the given mapping can collect unknown flags, being a pointer to a map with string keys.
+func isWildcard(cmd interface{}) bool {
+	t := reflect.TypeOf(target(cmd))
+	if t == nil || t.Kind() != reflect.Ptr {
+		return false
+	}
+	t = t.Elem()
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && values.IsSupported(t.Elem())
+}
+
+// wildcardFlags moves any flags remaining in the given arguments, before the given end position,
+// into the map of this commands WildcardKey, if it has one.
+func (c Commands) wildcardFlags(args arguments.Arguments, end int) error {
+	cmd, ok := c[WildcardKey]
+	if !ok {
+		return nil
+	}
+	if !isWildcard(cmd) {
+		return fmt.Errorf("%s must be mapped to a pointer to a map with string keys", WildcardKey)
+	}
+	return collectFlags(target(cmd), args, end)
+}
+
+// collectFlags moves any flags in the given arguments, before the given end position, into the given pointer to a map.
+func collectFlags(m interface{}, args arguments.Arguments, end int) error {
+	// collected into a copy of the map, assigned once all the flags are collected
+	mv := copyValue(reflect.ValueOf(m).Elem())
+	if mv.IsNil() {
+		mv.Set(reflect.MakeMap(mv.Type()))
+	}
+	flags := args.Flags()
+	collected := map[string]bool{}
+	// remove from the end, so the positions of the preceding flags remain valid
+	for i := len(flags) - 1; i >= 0; i-- {
+		arg := flags[i]
+		if arg.Position >= end {
+			continue
+		}
+		name := strings.TrimLeft(arg.Name, "-")
+		s := "true"
+		if len(arg.Parameters) > 0 {
+			arg.Parameters = arg.Parameters[:1]
+			s = arg.Parameters[0]
+		}
+		if err := args.Remove(arg); err != nil {
+			return err
+		}
+		if collected[name] {
+			// the last of a repeated flag is used, as with mapped flags
+			continue
+		}
+		collected[name] = true
+		v, err := values.ValueFromString(s, mv.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("%s %v", arg.Name, err)
+		}
+		mv.SetMapIndex(reflect.ValueOf(name).Convert(mv.Type().Key()), reflect.ValueOf(v))
+	}
+	assign(m, mv)
+	return nil
+}
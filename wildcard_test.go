@@ -0,0 +1,44 @@
+package commandgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWildcardFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		want       map[string]string
+		wantParams []string
+	}{
+		{name: "unknown flags", args: []string{"--level", "3", "--dry-run"}, want: map[string]string{"level": "3", "dry-run": "true"}},
+		{name: "assigned flag", args: []string{"--level=3", "x"}, want: map[string]string{"level": "3"}, wantParams: []string{"x"}},
+		{name: "last used", args: []string{"--level", "3", "--level", "4"}, want: map[string]string{"level": "4"}},
+		{name: "last used by any dashes", args: []string{"--level=3", "-level", "4", "--level", "5"}, want: map[string]string{"level": "5"}},
+		{name: "mapped flags not collected", args: []string{"--name", "a", "--level", "3"}, want: map[string]string{"level": "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var name string
+			var params []string
+			options := map[string]string{}
+			c := Commands{
+				"":       func(args ...string) { params = args },
+				"--name": &name,
+				"*":      &options,
+			}
+			if _, err := c.Run(tt.args...); err != nil {
+				t.Fatalf("Run(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(options, tt.want) {
+				t.Errorf("Run(%q) collected %v, want %v", tt.args, options, tt.want)
+			}
+			if len(params) > 0 || len(tt.wantParams) > 0 {
+				if !reflect.DeepEqual(params, tt.wantParams) {
+					t.Errorf("Run(%q) params %q, want %q", tt.args, params, tt.wantParams)
+				}
+			}
+		})
+	}
+}